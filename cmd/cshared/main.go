@@ -0,0 +1,63 @@
+// cshared 以 C 共享库的形式导出修复函数，供 Python、Rust、Node 等语言通过 FFI 调用
+//
+//	go build -buildmode=c-shared -o libllmjsonrepair.so ./cmd/cshared
+//
+// 返回的字符串（包括错误信息）由 Go 分配，调用方用完后必须通过 FreeString 释放
+package main
+
+/*
+#include <stdlib.h>
+*/
+import "C"
+
+import (
+	"encoding/json"
+	"fmt"
+	"unsafe"
+
+	"github.com/qdxiao/llmjsonrepair/pkg"
+)
+
+// Repair 修复 input 并返回格式化的 JSON 字符串
+// 失败时返回 NULL，并在 errOut 非空时写入错误信息
+//
+//export Repair
+func Repair(input *C.char, errOut **C.char) *C.char {
+	repaired, err := pkg.Repair(C.GoString(input))
+	if err != nil {
+		return fail(errOut, err)
+	}
+	return C.CString(repaired)
+}
+
+// Loads 修复 input 并返回紧凑的 JSON 字符串，便于调用方用本语言的 JSON 库解析
+// 失败时返回 NULL，并在 errOut 非空时写入错误信息
+//
+//export Loads
+func Loads(input *C.char, errOut **C.char) *C.char {
+	value, err := pkg.Loads(C.GoString(input))
+	if err != nil {
+		return fail(errOut, err)
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fail(errOut, fmt.Errorf("failed to marshal repaired json: %w", err))
+	}
+	return C.CString(string(data))
+}
+
+// FreeString 释放 Repair、Loads 返回的字符串及错误信息
+//
+//export FreeString
+func FreeString(s *C.char) {
+	C.free(unsafe.Pointer(s))
+}
+
+func fail(errOut **C.char, err error) *C.char {
+	if errOut != nil {
+		*errOut = C.CString(err.Error())
+	}
+	return nil
+}
+
+func main() {}
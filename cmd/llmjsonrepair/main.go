@@ -0,0 +1,204 @@
+// llmjsonrepair 是修复 LLM 输出的 JSON 的命令行工具
+//
+//	llmjsonrepair [repair] [-profile P] [-format F] [file...]  修复文件或标准输入，-format json 时输出 JSON 格式的诊断信息
+//	llmjsonrepair get [-r] query [file...]                     修复后按 jq 风格的路径（例如 .choices[0].message.content）提取值
+//	llmjsonrepair structs [-type T] file...                    根据一个或多个样本生成 Go 结构体定义
+//	llmjsonrepair repl [-profile P]                            交互式地粘贴片段，查看修复结果、修复报告与差异
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/qdxiao/llmjsonrepair/pkg"
+)
+
+func main() {
+	args := os.Args[1:]
+	command := "repair"
+	if len(args) > 0 {
+		if _, ok := commands[args[0]]; ok {
+			command, args = args[0], args[1:]
+		}
+	}
+	if err := commands[command](args); err != nil {
+		fmt.Fprintln(os.Stderr, "llmjsonrepair:", err)
+		os.Exit(1)
+	}
+}
+
+// commands 是所有子命令，参数不包括子命令名
+var commands = map[string]func(args []string) error{
+	"repair":  runRepair,
+	"get":     runGet,
+	"structs": runStructs,
+	"repl":    runREPL,
+}
+
+func runRepair(args []string) error {
+	fs := flag.NewFlagSet("repair", flag.ExitOnError)
+	profile := fs.String("profile", "", "model family preset: "+strings.Join(pkg.Profiles(), ", "))
+	format := fs.String("format", "text", "output format: text prints the repaired JSON, json prints the diagnostics of each input")
+	fs.Parse(args)
+
+	var opts []pkg.Option
+	if *profile != "" {
+		opts = append(opts, pkg.WithProfile(*profile))
+	}
+
+	inputs, err := readInputs(fs.Args())
+	if err != nil {
+		return err
+	}
+	switch *format {
+	case "text":
+	case "json":
+		return printReports(fs.Args(), inputs, opts)
+	default:
+		return fmt.Errorf("unknown format %q: must be text or json", *format)
+	}
+	for _, input := range inputs {
+		repaired, err := pkg.Repair(input, opts...)
+		if err != nil {
+			return err
+		}
+		fmt.Println(repaired)
+	}
+	return nil
+}
+
+// printReports 以 JSON 数组输出每个输入的诊断报告，标准输入的文件名为 "-"
+func printReports(paths, inputs []string, opts []pkg.Option) error {
+	reports := make([]pkg.Report, len(inputs))
+	for i, input := range inputs {
+		file := "-"
+		if i < len(paths) {
+			file = paths[i]
+		}
+		reports[i] = pkg.NewReport(file, pkg.Diagnose(input, opts...))
+	}
+	out, err := json.MarshalIndent(reports, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal reports: %w", err)
+	}
+	fmt.Println(string(out))
+	return nil
+}
+
+func runGet(args []string) error {
+	fs := flag.NewFlagSet("get", flag.ExitOnError)
+	raw := fs.Bool("r", false, "print strings without quotes")
+	fs.Parse(args)
+	if fs.NArg() == 0 {
+		return fmt.Errorf("usage: llmjsonrepair get [-r] query [file...]")
+	}
+
+	pointer, err := queryPointer(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	inputs, err := readInputs(fs.Args()[1:])
+	if err != nil {
+		return err
+	}
+	for _, input := range inputs {
+		value, err := pkg.RepairPointer(input, pointer)
+		if err != nil {
+			return err
+		}
+		if s, ok := value.(string); ok && *raw {
+			fmt.Println(s)
+			continue
+		}
+		out, err := json.MarshalIndent(value, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal value: %w", err)
+		}
+		fmt.Println(string(out))
+	}
+	return nil
+}
+
+// queryPointer 将 .a.b[0]、.["key"] 形式的 jq 路径转换为 JSON Pointer
+func queryPointer(query string) (string, error) {
+	if !strings.HasPrefix(query, ".") {
+		return "", fmt.Errorf("invalid query %q: must start with '.'", query)
+	}
+	escape := strings.NewReplacer("~", "~0", "/", "~1")
+	var sb strings.Builder
+	rest := query
+	for rest != "" && rest != "." {
+		switch {
+		case strings.HasPrefix(rest, ".[") || strings.HasPrefix(rest, "["):
+			rest = strings.TrimPrefix(strings.TrimPrefix(rest, "."), "[")
+			end := strings.Index(rest, "]")
+			if end < 0 {
+				return "", fmt.Errorf("invalid query %q: unclosed '['", query)
+			}
+			segment := rest[:end]
+			if unquoted, err := strconv.Unquote(segment); err == nil {
+				segment = unquoted
+			} else if _, err := strconv.Atoi(segment); err != nil {
+				return "", fmt.Errorf("invalid query %q: bad index %q", query, segment)
+			}
+			sb.WriteString("/" + escape.Replace(segment))
+			rest = rest[end+1:]
+		case rest[0] == '.':
+			end := strings.IndexAny(rest[1:], ".[")
+			if end < 0 {
+				end = len(rest) - 1
+			}
+			if end == 0 {
+				return "", fmt.Errorf("invalid query %q: empty key", query)
+			}
+			sb.WriteString("/" + escape.Replace(rest[1:end+1]))
+			rest = rest[end+1:]
+		default:
+			return "", fmt.Errorf("invalid query %q: unexpected %q", query, rest)
+		}
+	}
+	return sb.String(), nil
+}
+
+func runStructs(args []string) error {
+	fs := flag.NewFlagSet("structs", flag.ExitOnError)
+	typeName := fs.String("type", "Response", "name of the root type")
+	packageName := fs.String("package", "main", "package clause of the generated file")
+	fs.Parse(args)
+
+	samples, err := readInputs(fs.Args())
+	if err != nil {
+		return err
+	}
+	src, err := pkg.GenerateGoStructs(*typeName, samples)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("package %s\n\n%s", *packageName, src)
+	return nil
+}
+
+// readInputs 读取每个文件的内容，没有文件参数时读取标准输入
+func readInputs(paths []string) ([]string, error) {
+	if len(paths) == 0 {
+		data, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read stdin: %w", err)
+		}
+		return []string{string(data)}, nil
+	}
+	inputs := make([]string, 0, len(paths))
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", path, err)
+		}
+		inputs = append(inputs, string(data))
+	}
+	return inputs, nil
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/qdxiao/llmjsonrepair/pkg"
+)
+
+func runREPL(args []string) error {
+	fs := flag.NewFlagSet("repl", flag.ExitOnError)
+	profile := fs.String("profile", "", "model family preset: "+strings.Join(pkg.Profiles(), ", "))
+	fs.Parse(args)
+
+	var opts []pkg.Option
+	if *profile != "" {
+		opts = append(opts, pkg.WithProfile(*profile))
+	}
+	return repl(os.Stdin, os.Stdout, opts)
+}
+
+// repl 逐段读取输入，每段以空行结束，输出修复结果、修复报告以及与输入的逐行差异
+func repl(r io.Reader, w io.Writer, opts []pkg.Option) error {
+	fmt.Fprintln(w, "paste a snippet and end it with an empty line, Ctrl-D to quit")
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	var lines []string
+	prompt := func() {
+		if len(lines) == 0 {
+			fmt.Fprint(w, "> ")
+		} else {
+			fmt.Fprint(w, ". ")
+		}
+	}
+
+	prompt()
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.TrimSpace(line) != "" {
+			lines = append(lines, line)
+			prompt()
+			continue
+		}
+		if len(lines) > 0 {
+			evaluate(w, strings.Join(lines, "\n"), opts)
+			lines = lines[:0]
+		}
+		prompt()
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read stdin: %w", err)
+	}
+	if len(lines) > 0 {
+		fmt.Fprintln(w)
+		evaluate(w, strings.Join(lines, "\n"), opts)
+	}
+	return nil
+}
+
+// evaluate 修复一段输入并打印结果
+func evaluate(w io.Writer, input string, opts []pkg.Option) {
+	var explanation string
+	repaired, err := pkg.Repair(input, append(opts, pkg.WithExplanation(&explanation))...)
+	if err != nil {
+		fmt.Fprintf(w, "error: %v\n\n", err)
+		return
+	}
+
+	fmt.Fprintf(w, "--- repaired\n%s\n", repaired)
+	fmt.Fprintln(w, "--- report")
+	if explanation == "" {
+		fmt.Fprintln(w, "no repairs were needed")
+	} else {
+		fmt.Fprintln(w, strings.TrimRight(explanation, "\n"))
+	}
+	fmt.Fprintln(w, "--- diff")
+	for _, line := range diffLines(strings.Split(input, "\n"), strings.Split(repaired, "\n")) {
+		fmt.Fprintln(w, line)
+	}
+	fmt.Fprintln(w)
+}
+
+// diffLines 基于最长公共子序列比较两组行，返回以 "  "、"- "、"+ " 开头的差异行
+func diffLines(a, b []string) []string {
+	// lcs[i][j] 是 a[i:] 与 b[j:] 的最长公共子序列长度
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if strings.TrimSpace(a[i]) == strings.TrimSpace(b[j]) {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	out := make([]string, 0, len(a)+len(b))
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && strings.TrimSpace(a[i]) == strings.TrimSpace(b[j]):
+			out = append(out, "  "+b[j])
+			i++
+			j++
+		case i < len(a) && (j == len(b) || lcs[i+1][j] >= lcs[i][j+1]):
+			out = append(out, "- "+a[i])
+			i++
+		default:
+			out = append(out, "+ "+b[j])
+			j++
+		}
+	}
+	return out
+}
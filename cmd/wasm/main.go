@@ -0,0 +1,69 @@
+//go:build js && wasm
+
+// wasm 将修复函数导出给 JavaScript，使浏览器端与后端使用完全相同的修复规则
+//
+//	GOOS=js GOARCH=wasm go build -o llmjsonrepair.wasm ./cmd/wasm
+//
+// 加载后在全局对象上提供 llmjsonrepair.repair(text) 与 llmjsonrepair.loads(text)，
+// 两者都返回 {result, error}：repair 的 result 为修复后的 JSON 字符串，loads 的 result 为对应的 JavaScript 值
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"syscall/js"
+
+	"github.com/qdxiao/llmjsonrepair/pkg"
+)
+
+func main() {
+	js.Global().Set("llmjsonrepair", js.ValueOf(map[string]interface{}{
+		"repair": js.FuncOf(repair),
+		"loads":  js.FuncOf(loads),
+	}))
+	// 保持运行，供 JavaScript 持续调用
+	select {}
+}
+
+func repair(_ js.Value, args []js.Value) interface{} {
+	input, err := inputArg(args)
+	if err != nil {
+		return result(nil, err)
+	}
+	repaired, err := pkg.Repair(input)
+	if err != nil {
+		return result(nil, err)
+	}
+	return result(repaired, nil)
+}
+
+func loads(_ js.Value, args []js.Value) interface{} {
+	input, err := inputArg(args)
+	if err != nil {
+		return result(nil, err)
+	}
+	value, err := pkg.Loads(input)
+	if err != nil {
+		return result(nil, err)
+	}
+	// 通过 JSON.parse 转换，避免逐个映射 Go 类型
+	data, err := json.Marshal(value)
+	if err != nil {
+		return result(nil, fmt.Errorf("failed to marshal repaired json: %w", err))
+	}
+	return result(js.Global().Get("JSON").Call("parse", string(data)), nil)
+}
+
+func inputArg(args []js.Value) (string, error) {
+	if len(args) != 1 || args[0].Type() != js.TypeString {
+		return "", fmt.Errorf("expected a single string argument")
+	}
+	return args[0].String(), nil
+}
+
+func result(value interface{}, err error) interface{} {
+	if err != nil {
+		return js.ValueOf(map[string]interface{}{"result": nil, "error": err.Error()})
+	}
+	return js.ValueOf(map[string]interface{}{"result": value, "error": nil})
+}
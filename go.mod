@@ -1,3 +1,5 @@
 module github.com/qdxiao/llmjsonrepair
 
 go 1.24.0
+
+require golang.org/x/text v0.30.0
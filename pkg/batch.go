@@ -0,0 +1,49 @@
+package pkg
+
+import "sync"
+
+// Result 是 RepairBatch 中单个输入的修复结果
+type Result struct {
+	Repaired string
+	Err      error
+}
+
+// RepairBatch 使用 workers 个并发协程修复多个输入，结果顺序与输入一致，单个输入的错误记录在对应结果中
+// 每个协程复用一个解析器；WithExplanation、WithStats 等写入外部变量的选项不应在这里使用
+func RepairBatch(inputs []string, workers int, opts ...Option) []Result {
+	results := make([]Result, len(inputs))
+	runWorkers(len(inputs), workers, func() func(i int) {
+		p := NewParser("", opts...)
+		return func(i int) {
+			p.Reset(inputs[i])
+			repaired, err := p.repair()
+			results[i] = Result{Repaired: repaired, Err: err}
+		}
+	})
+	return results
+}
+
+// runWorkers 使用 workers 个协程处理下标 0 到 n-1 的任务
+// newWorker 在每个协程启动时调用一次，返回该协程处理单个任务的函数
+func runWorkers(n, workers int, newWorker func() func(i int)) {
+	if workers <= 0 {
+		workers = 1
+	}
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			work := newWorker()
+			for i := range jobs {
+				work(i)
+			}
+		}()
+	}
+	for i := 0; i < n; i++ {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+}
@@ -0,0 +1,125 @@
+package pkg
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"sync"
+)
+
+// WithCache 按输入的哈希缓存最近 size 个修复结果，命中时跳过解析
+// 缓存保存在返回的 Option 中：需要在多次调用之间复用同一个 Option 值才能命中，
+// 并且同一个缓存只应与相同的其他选项一起使用
+func WithCache(size int) Option {
+	cache := newRepairCache(size)
+	return func(p *Parser) {
+		p.cache = cache
+	}
+}
+
+// cacheEntry 是一次 load 的结果，包括用于诊断与统计的状态
+type cacheEntry struct {
+	key         [sha256.Size]byte
+	value       interface{}
+	err         error
+	diagnostics []Diagnostic
+	comments    []Comment
+	fallback    bool
+}
+
+// repairCache 是并发安全的 LRU 缓存
+type repairCache struct {
+	mu    sync.Mutex
+	size  int
+	order *list.List
+	items map[[sha256.Size]byte]*list.Element
+}
+
+func newRepairCache(size int) *repairCache {
+	return &repairCache{
+		size:  size,
+		order: list.New(),
+		items: make(map[[sha256.Size]byte]*list.Element),
+	}
+}
+
+func (c *repairCache) get(key [sha256.Size]byte) (*cacheEntry, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	elem, ok := c.items[key]
+	if !ok {
+		return nil, false
+	}
+	c.order.MoveToFront(elem)
+	return elem.Value.(*cacheEntry), true
+}
+
+func (c *repairCache) put(entry *cacheEntry) {
+	if c.size <= 0 {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if elem, ok := c.items[entry.key]; ok {
+		elem.Value = entry
+		c.order.MoveToFront(elem)
+		return
+	}
+	c.items[entry.key] = c.order.PushFront(entry)
+	for c.order.Len() > c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.items, oldest.Value.(*cacheEntry).key)
+	}
+}
+
+// loadCached 在缓存中查找结果，未命中时执行 load 并写入缓存
+// 缓存的值会被复制后返回，调用方修改结果不会影响缓存
+// 命中时缓存的诊断信息会重新报告给 Logger，WithStats、WithExplanation 的结果与未命中时相同
+func (p *Parser) loadCached() (interface{}, error) {
+	key := sha256.Sum256(p.inputBytes())
+	if entry, ok := p.cache.get(key); ok {
+		// Reset 已经报告过解码阶段的诊断信息，这里只向 Logger 重放之后的部分，使命中与未命中时的日志一致
+		replay := entry.diagnostics[min(len(p.diagnostics), len(entry.diagnostics)):]
+		if _, nop := p.logger.(nopLogger); !nop && len(replay) > 0 {
+			p.materialize()
+			for _, d := range replay {
+				p.logRepair(d)
+			}
+		}
+		p.diagnostics = append(p.diagnostics[:0], entry.diagnostics...)
+		p.capturedComments = append(p.capturedComments[:0], entry.comments...)
+		p.fallback = entry.fallback
+		p.finish()
+		return copyValue(entry.value), entry.err
+	}
+
+	value, err := p.loadUncached()
+	p.cache.put(&cacheEntry{
+		key:         key,
+		value:       copyValue(value),
+		err:         err,
+		diagnostics: append([]Diagnostic(nil), p.diagnostics...),
+		comments:    append([]Comment(nil), p.capturedComments...),
+		fallback:    p.fallback,
+	})
+	return value, err
+}
+
+// copyValue 深拷贝解析结果中的对象和数组
+func copyValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(v))
+		for key, child := range v {
+			out[key] = copyValue(child)
+		}
+		return out
+	case []interface{}:
+		out := make([]interface{}, len(v))
+		for i, child := range v {
+			out[i] = copyValue(child)
+		}
+		return out
+	}
+	return value
+}
@@ -0,0 +1,85 @@
+package pkg
+
+import "sort"
+
+// Candidate 是输入的一种修复方式
+type Candidate struct {
+	Repaired     string       // 格式化后的 JSON
+	Description  string       // 该候选采用的解释方式
+	Plausibility float64      // 可信度，取值 0 到 1，越大越可信
+	Diagnostics  []Diagnostic // 该解释方式下记录的修复
+}
+
+// candidateVariants 是生成候选时依次尝试的解释方式
+var candidateVariants = []struct {
+	description string
+	opts        []Option
+}{
+	{"default heuristics", nil},
+	{"commas inside numbers are decimal separators", []Option{WithDecimalComma()}},
+	{"numbers with unit suffixes are strings", []Option{WithUnits(UnitsAsString)}},
+	{"unit suffixes are separate fields", []Option{WithUnits(UnitsSplit)}},
+	{"template placeholders are strings", []Option{WithTemplatePlaceholders()}},
+	{"keys without values are boolean flags", []Option{WithFlagKeys()}},
+	{"key=value lines form an object", []Option{WithKeyValuePairs()}},
+	{"braced collections are objects rather than sets", []Option{withoutSetLiterals()}},
+}
+
+// withoutSetLiterals 不把没有冒号的花括号当作集合字面量，只用于生成候选
+func withoutSetLiterals() Option {
+	return func(p *Parser) {
+		p.noSetLiterals = true
+	}
+}
+
+// severityCost 是每条诊断按严重程度降低可信度的权重
+var severityCost = map[Severity]float64{
+	SeverityInfo:    0.1,
+	SeverityWarning: 0.5,
+	SeverityError:   1,
+}
+
+// RepairCandidates 用不同的解释方式修复有歧义的输入，返回最多 max 个结果不同的候选，按可信度从高到低排列
+// 可信度综合了参与解析的内容比例和所需修复的数量与严重程度，便于下游校验器或模型从中选择
+func RepairCandidates(jsonStr string, max int, opts ...Option) ([]Candidate, error) {
+	candidates := make([]Candidate, 0, len(candidateVariants))
+	seen := make(map[string]bool)
+	var firstErr error
+	for _, variant := range candidateVariants {
+		p := NewParser(jsonStr, append(append([]Option(nil), opts...), variant.opts...)...)
+		repaired, err := p.repair()
+		if err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		if seen[repaired] {
+			continue
+		}
+		seen[repaired] = true
+
+		diagnostics := p.Diagnostics()
+		cost := 0.0
+		for _, d := range diagnostics {
+			cost += severityCost[d.Severity]
+		}
+		candidates = append(candidates, Candidate{
+			Repaired:     repaired,
+			Description:  variant.description,
+			Plausibility: p.Stats().Coverage / (1 + cost),
+			Diagnostics:  diagnostics,
+		})
+	}
+	if len(candidates) == 0 {
+		return nil, firstErr
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].Plausibility > candidates[j].Plausibility
+	})
+	if max > 0 && len(candidates) > max {
+		candidates = candidates[:max]
+	}
+	return candidates, nil
+}
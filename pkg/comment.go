@@ -0,0 +1,69 @@
+package pkg
+
+import "strings"
+
+// Comment 是输入中的一条 // 或 /* */ 注释
+// 与成员位于同一行、紧跟在成员之后的注释属于该成员，其余注释属于其后的第一个对象键，
+// 位于成员值内部的注释属于该成员
+type Comment struct {
+	Text   string // 去掉注释符号和首尾空白后的内容
+	Offset int    // 注释在输入中的字符（rune）偏移
+	Line   int    // 从 1 开始的行号
+	Column int    // 从 1 开始的列号
+	Key    string // 注释所说明的对象键，没有对应的键时为空
+}
+
+// atComment 判断当前位置是否为注释的开始
+func (p *Parser) atComment() bool {
+	return p.comments && (p.hasPrefixAt(p.index, "//") || p.hasPrefixAt(p.index, "/*"))
+}
+
+// skipComment 跳过当前位置的一条注释并记录下来，未闭合的块注释延伸到输入末尾
+func (p *Parser) skipComment() {
+	start := p.index
+	var text string
+	if p.hasPrefixAt(start, "//") {
+		end := start + 2
+		for end < len(p.jsonStr) && p.jsonStr[end] != '\n' {
+			end++
+		}
+		text, p.index = string(p.jsonStr[start+2:end]), end
+	} else {
+		end := start + 2
+		for end < len(p.jsonStr) && !p.hasPrefixAt(end, "*/") {
+			end++
+		}
+		text, p.index = string(p.jsonStr[start+2:end]), min(end+2, len(p.jsonStr))
+	}
+	p.addDiagnostic(KindComment, SeverityInfo, start, p.index-start,
+		"removed a comment", "remove comments from the JSON")
+	if p.commentsOut == nil {
+		return
+	}
+
+	line, column := p.position(start)
+	p.capturedComments = append(p.capturedComments, Comment{
+		Text:   strings.TrimSpace(text),
+		Offset: start,
+		Line:   line,
+		Column: column,
+	})
+	// 紧跟在成员之后的同一行注释属于该成员
+	if p.memberEnd >= 0 && p.attachedComments == len(p.capturedComments)-1 &&
+		!strings.ContainsRune(string(p.jsonStr[p.memberEnd:start]), '\n') {
+		p.capturedComments[p.attachedComments].Key = p.memberKey
+		p.attachedComments++
+	}
+}
+
+// attachComments 将尚未关联键的注释关联到 key；memberDone 表示成员已经结束，其后同一行的注释也属于它
+func (p *Parser) attachComments(key string, memberDone bool) {
+	for i := p.attachedComments; i < len(p.capturedComments); i++ {
+		p.capturedComments[i].Key = key
+	}
+	p.attachedComments = len(p.capturedComments)
+	p.memberKey, p.memberEnd = key, -1
+	if memberDone {
+		p.memberEnd = p.index
+	}
+}
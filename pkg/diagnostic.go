@@ -0,0 +1,166 @@
+package pkg
+
+import (
+	"sort"
+)
+
+// Severity 表示诊断信息的严重程度
+type Severity int
+
+const (
+	// SeverityInfo 表示不影响数据的格式问题，例如多余的逗号
+	SeverityInfo Severity = iota
+	// SeverityWarning 表示需要推断结构才能修复的问题，例如缺少括号
+	SeverityWarning
+	// SeverityError 表示修复时可能丢失或改变数据的问题，例如被跳过的字符
+	SeverityError
+)
+
+func (s Severity) String() string {
+	switch s {
+	case SeverityInfo:
+		return "info"
+	case SeverityWarning:
+		return "warning"
+	case SeverityError:
+		return "error"
+	}
+	return "unknown"
+}
+
+// RepairKind 标识修复规则的类型
+type RepairKind string
+
+const (
+	KindSkippedGarbage       RepairKind = "skipped_garbage"
+	KindUnclosedObject       RepairKind = "unclosed_object"
+	KindUnclosedArray        RepairKind = "unclosed_array"
+	KindUnclosedString       RepairKind = "unclosed_string"
+	KindMissingQuotes        RepairKind = "missing_quotes"
+	KindSingleQuotes         RepairKind = "single_quotes"
+	KindMissingColon         RepairKind = "missing_colon"
+	KindMissingComma         RepairKind = "missing_comma"
+	KindExtraComma           RepairKind = "extra_comma"
+	KindSemicolonSeparator   RepairKind = "semicolon_separator"
+	KindInvalidNumber        RepairKind = "invalid_number"
+	KindMultipleDocuments    RepairKind = "multiple_documents"
+	KindTruncatedString      RepairKind = "truncated_string"
+	KindSetLiteral           RepairKind = "set_literal"
+	KindCallExpression       RepairKind = "call_expression"
+	KindTripleQuotes         RepairKind = "triple_quotes"
+	KindBacktickQuotes       RepairKind = "backtick_quotes"
+	KindTranscoded           RepairKind = "transcoded"
+	KindInvalidUTF8          RepairKind = "invalid_utf8"
+	KindSanitizedKey         RepairKind = "sanitized_key"
+	KindUnflattenedKeys      RepairKind = "unflattened_keys"
+	KindCustomRule           RepairKind = "custom_rule"
+	KindShapeCoerced         RepairKind = "shape_coerced"
+	KindNewlineSeparator     RepairKind = "newline_separator"
+	KindDecimalComma         RepairKind = "decimal_comma"
+	KindUnitSuffix           RepairKind = "unit_suffix"
+	KindTruncatedArray       RepairKind = "truncated_array"
+	KindTemplatePlaceholder  RepairKind = "template_placeholder"
+	KindNormalizedKey        RepairKind = "normalized_key"
+	KindTruncatedNumber      RepairKind = "truncated_number"
+	KindWrappedScalar        RepairKind = "wrapped_scalar"
+	KindFullWidthPunctuation RepairKind = "full_width_punctuation"
+	KindXMLTag               RepairKind = "xml_tag"
+	KindFlagKey              RepairKind = "flag_key"
+	KindKeyValuePairs        RepairKind = "key_value_pairs"
+	KindComment              RepairKind = "comment"
+	KindUnparseableValue     RepairKind = "unparseable_value"
+	KindKeyCase              RepairKind = "key_case"
+	KindSparseFlatKey        RepairKind = "sparse_flat_key"
+	KindUnitMemberRenamed    RepairKind = "unit_member_renamed"
+)
+
+// Diagnostic 描述修复过程中发现的一个问题
+type Diagnostic struct {
+	Kind       RepairKind
+	Severity   Severity
+	Offset     int    // 问题在输入中的字符（rune）偏移，后处理阶段产生的问题为 -1
+	Length     int    // 问题涉及的字符数
+	Line       int    // 从 1 开始的行号，位置未知时为 0
+	Column     int    // 从 1 开始的列号，位置未知时为 0
+	Path       string // 后处理阶段产生的问题所涉及值的路径，例如 $.items[0].name
+	Message    string
+	Suggestion string
+}
+
+// Diagnose 运行修复流程但不输出结果，返回输入中发现的所有问题
+// 对于本身合法的 JSON，只返回后处理选项（例如 WithKeyCase、WithRedactKeys）产生的问题，没有时返回空列表
+func Diagnose(jsonStr string, opts ...Option) []Diagnostic {
+	parser := NewParser(jsonStr, opts...)
+	_, _ = parser.load()
+	return parser.Diagnostics()
+}
+
+// Diagnostics 返回本次解析记录的诊断信息（按偏移排序），并填充行号与列号
+func (p *Parser) Diagnostics() []Diagnostic {
+	out := make([]Diagnostic, len(p.diagnostics))
+	for i, d := range p.diagnostics {
+		if d.Offset >= 0 {
+			d.Line, d.Column = p.position(d.Offset)
+		}
+		out[i] = d
+	}
+	// 位置未知的问题排在最后
+	sort.SliceStable(out, func(i, j int) bool {
+		if out[i].Offset < 0 || out[j].Offset < 0 {
+			return out[j].Offset < 0 && out[i].Offset >= 0
+		}
+		return out[i].Offset < out[j].Offset
+	})
+	return out
+}
+
+// addDiagnostic 记录一个诊断信息，连续跳过的字符会被合并为一条
+func (p *Parser) addDiagnostic(kind RepairKind, severity Severity, offset, length int, message, suggestion string) {
+	if n := len(p.diagnostics); n > 0 {
+		last := &p.diagnostics[n-1]
+		if kind == KindSkippedGarbage && last.Kind == kind && last.Offset+last.Length == offset {
+			last.Length += length
+			return
+		}
+	}
+	d := Diagnostic{
+		Kind:       kind,
+		Severity:   severity,
+		Offset:     offset,
+		Length:     length,
+		Message:    message,
+		Suggestion: suggestion,
+	}
+	p.diagnostics = append(p.diagnostics, d)
+	if !p.decoding {
+		p.logRepair(d)
+	}
+}
+
+// addPathDiagnostic 记录一个后处理阶段产生的、以值路径定位的诊断信息
+func (p *Parser) addPathDiagnostic(kind RepairKind, severity Severity, path, message, suggestion string) {
+	d := Diagnostic{
+		Kind:       kind,
+		Severity:   severity,
+		Offset:     -1,
+		Path:       path,
+		Message:    message,
+		Suggestion: suggestion,
+	}
+	p.diagnostics = append(p.diagnostics, d)
+	p.logRepair(d)
+}
+
+// position 将字符偏移转换为从 1 开始的行号和列号
+func (p *Parser) position(offset int) (int, int) {
+	line, column := 1, 1
+	for i := 0; i < offset && i < len(p.jsonStr); i++ {
+		if p.jsonStr[i] == '\n' {
+			line++
+			column = 1
+		} else {
+			column++
+		}
+	}
+	return line, column
+}
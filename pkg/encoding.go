@@ -0,0 +1,172 @@
+package pkg
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding/unicode"
+	"golang.org/x/text/transform"
+)
+
+const utf8BOM = "\xef\xbb\xbf"
+
+// InvalidUTF8Policy 决定输入中出现非法 UTF-8 字节序列时的处理方式
+type InvalidUTF8Policy int
+
+const (
+	// InvalidUTF8Replace 将非法字节序列替换为 U+FFFD（默认）
+	InvalidUTF8Replace InvalidUTF8Policy = iota
+	// InvalidUTF8Strip 删除非法字节序列
+	InvalidUTF8Strip
+	// InvalidUTF8Error 遇到非法字节序列时返回 ErrInvalidUTF8
+	InvalidUTF8Error
+)
+
+// ErrInvalidUTF8 表示输入包含非法的 UTF-8 字节序列
+var ErrInvalidUTF8 = errors.New("input contains invalid UTF-8")
+
+// decodeInput 在解析前统一输入编码，并保证结果是合法的 UTF-8
+func (p *Parser) decodeInput(s string) string {
+	return p.sanitizeUTF8(p.transcode(s))
+}
+
+// sanitizeUTF8 按 WithInvalidUTF8 指定的策略处理非法字节序列
+func (p *Parser) sanitizeUTF8(s string) string {
+	if utf8.ValidString(s) {
+		return s
+	}
+
+	i := 0
+	for i < len(s) {
+		r, size := utf8.DecodeRuneInString(s[i:])
+		if r == utf8.RuneError && size == 1 {
+			break
+		}
+		i += size
+	}
+	offset := utf8.RuneCountInString(s[:i])
+
+	switch p.invalidUTF8 {
+	case InvalidUTF8Error:
+		p.inputErr = fmt.Errorf("%w at byte offset %d", ErrInvalidUTF8, i)
+		return s
+	case InvalidUTF8Strip:
+		p.addDiagnostic(KindInvalidUTF8, SeverityWarning, offset, 0,
+			"invalid UTF-8 bytes were removed", "send the payload as valid UTF-8")
+		return strings.ToValidUTF8(s, "")
+	default:
+		p.addDiagnostic(KindInvalidUTF8, SeverityWarning, offset, 1,
+			"invalid UTF-8 bytes were replaced with U+FFFD", "send the payload as valid UTF-8")
+		return strings.ToValidUTF8(s, "\uFFFD")
+	}
+}
+
+// transcode 按 WithCharset 指定的字符集转换输入，
+// 否则去除 UTF-8 BOM，并将 UTF-16 输入转换为 UTF-8
+func (p *Parser) transcode(s string) string {
+	if p.charset != nil {
+		decoded, err := p.charset.NewDecoder().String(s)
+		if err != nil {
+			p.inputErr = fmt.Errorf("failed to decode input: %w", err)
+			return s
+		}
+		return decoded
+	}
+
+	if strings.HasPrefix(s, utf8BOM) {
+		return s[len(utf8BOM):]
+	}
+
+	order, bom := detectUTF16(s)
+	if order == nil {
+		return s
+	}
+	if bom {
+		s = s[2:]
+	}
+	p.addDiagnostic(KindTranscoded, SeverityInfo, 0, 0,
+		"UTF-16 input was converted to UTF-8", "send the payload as UTF-8")
+	return decodeUTF16(s, order)
+}
+
+// detectUTF16 根据 BOM 或零字节的分布判断输入是否为 UTF-16，返回字节序以及是否带 BOM
+func detectUTF16(s string) (binary.ByteOrder, bool) {
+	if len(s) < 2 {
+		return nil, false
+	}
+	switch {
+	case s[0] == 0xFE && s[1] == 0xFF:
+		return binary.BigEndian, true
+	case s[0] == 0xFF && s[1] == 0xFE:
+		return binary.LittleEndian, true
+	}
+
+	// 没有 BOM 时，JSON 中大量的 ASCII 字符在 UTF-16 下会使奇数或偶数位置出现零字节
+	var evenZeros, oddZeros int
+	for i := 0; i < len(s); i++ {
+		if s[i] != 0 {
+			continue
+		}
+		if i%2 == 0 {
+			evenZeros++
+		} else {
+			oddZeros++
+		}
+	}
+	// 另一侧的零字节来自 '一'、'Ā' 这类低字节为零的字符，只要求它们远少于主要一侧
+	half := len(s) / 2
+	switch {
+	case oddZeros > half*2/5 && evenZeros*4 < oddZeros:
+		return binary.LittleEndian, false
+	case evenZeros > half*2/5 && oddZeros*4 < evenZeros:
+		return binary.BigEndian, false
+	}
+	return nil, false
+}
+
+// decodeUTF16 将 UTF-16 字节序列解码为 UTF-8 字符串，末尾不完整的字节会被丢弃
+func decodeUTF16(s string, order binary.ByteOrder) string {
+	b := []byte(s)
+	units := make([]uint16, 0, len(b)/2)
+	for i := 0; i+1 < len(b); i += 2 {
+		units = append(units, order.Uint16(b[i:]))
+	}
+	return string(utf16.Decode(units))
+}
+
+// decodeStream 在按字符切分文档之前将 r 转换为 UTF-8：使用 WithCharset 时按该字符集解码，
+// 否则根据开头的 BOM 识别 UTF-16；流式输入无法预读全部内容，不带 BOM 的 UTF-16 不会被识别
+// 返回的选项关闭了 WithCharset，切分出的文档已经是 UTF-8，不会被再次转码
+func decodeStream(r io.Reader, opts []Option) (io.Reader, []Option) {
+	p := NewParser("", opts...)
+	if p.charset != nil {
+		return transform.NewReader(r, p.charset.NewDecoder()), append(opts[:len(opts):len(opts)], withoutCharset())
+	}
+
+	br := bufio.NewReader(r)
+	head, _ := br.Peek(2)
+	order, bom := detectUTF16(string(head))
+	if !bom {
+		return br, opts
+	}
+	switch order {
+	case binary.LittleEndian:
+		return transform.NewReader(br, unicode.UTF16(unicode.LittleEndian, unicode.UseBOM).NewDecoder()), opts
+	case binary.BigEndian:
+		return transform.NewReader(br, unicode.UTF16(unicode.BigEndian, unicode.UseBOM).NewDecoder()), opts
+	}
+	return br, opts
+}
+
+// withoutCharset 撤销 WithCharset，用于已经由 decodeStream 解码的内容
+func withoutCharset() Option {
+	return func(p *Parser) {
+		p.charset = nil
+	}
+}
@@ -0,0 +1,77 @@
+package pkg
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strconv"
+)
+
+// SemanticallyEqual 修复两个输入并深度比较结果，忽略键的顺序、空白以及数字的写法（例如 1、1.0 与 1e0）
+// 数字按 Loads 解析后的值比较，本身合法的输入中超出 float64 精度的数字可能被视为相等
+func SemanticallyEqual(a, b string, opts ...Option) (bool, error) {
+	va, err := Loads(a, opts...)
+	if err != nil {
+		return false, fmt.Errorf("failed to repair first input: %w", err)
+	}
+	vb, err := Loads(b, opts...)
+	if err != nil {
+		return false, fmt.Errorf("failed to repair second input: %w", err)
+	}
+	return valuesEqual(va, vb), nil
+}
+
+// valuesEqual 深度比较两个解析结果，数字按数值比较
+func valuesEqual(a, b interface{}) bool {
+	if x, ok := numberRat(a); ok {
+		y, ok := numberRat(b)
+		return ok && x.Cmp(y) == 0
+	}
+	switch x := a.(type) {
+	case map[string]interface{}:
+		y, ok := b.(map[string]interface{})
+		if !ok || len(x) != len(y) {
+			return false
+		}
+		for key, xv := range x {
+			yv, ok := y[key]
+			if !ok || !valuesEqual(xv, yv) {
+				return false
+			}
+		}
+		return true
+	case []interface{}:
+		y, ok := b.([]interface{})
+		if !ok || len(x) != len(y) {
+			return false
+		}
+		for i := range x {
+			if !valuesEqual(x[i], y[i]) {
+				return false
+			}
+		}
+		return true
+	case string:
+		y, ok := b.(string)
+		return ok && x == y
+	case bool:
+		y, ok := b.(bool)
+		return ok && x == y
+	case nil:
+		return b == nil
+	}
+	return false
+}
+
+// numberRat 将解析结果中的数字转换为精确的有理数，不同类型与写法的数字可以直接比较
+func numberRat(v interface{}) (*big.Rat, bool) {
+	switch n := v.(type) {
+	case int64:
+		return new(big.Rat).SetInt64(n), true
+	case float64:
+		return new(big.Rat).SetString(strconv.FormatFloat(n, 'g', -1, 64))
+	case json.Number:
+		return new(big.Rat).SetString(n.String())
+	}
+	return nil, false
+}
@@ -0,0 +1,115 @@
+package pkg
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Explain 修复JSON并返回一段描述输入问题及修复方式的文字说明
+// 对于本身合法的 JSON，返回空字符串
+func Explain(jsonStr string, opts ...Option) string {
+	var explanation string
+	opts = append(opts, WithExplanation(&explanation))
+	_, _ = Loads(jsonStr, opts...)
+	return explanation
+}
+
+// explain 将诊断信息转换为逐行的文字说明
+func explain(diagnostics []Diagnostic) string {
+	lines := make([]string, 0, len(diagnostics))
+	for _, d := range diagnostics {
+		lines = append(lines, explainDiagnostic(d))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// explainDiagnostic 生成单条诊断信息的说明
+func explainDiagnostic(d Diagnostic) string {
+	at := fmt.Sprintf("line %d, column %d", d.Line, d.Column)
+	if d.Offset < 0 {
+		at = d.Path
+	}
+	switch d.Kind {
+	case KindSkippedGarbage:
+		return fmt.Sprintf("%d unexpected character(s) at %s were not part of the JSON and were skipped.", d.Length, at)
+	case KindUnclosedObject:
+		return fmt.Sprintf("The object starting at %s was never closed; a `}` was appended.", at)
+	case KindUnclosedArray:
+		return fmt.Sprintf("The array starting at %s was never closed; a `]` was appended.", at)
+	case KindUnclosedString:
+		return fmt.Sprintf("The string starting at %s was never closed; a closing quote was appended.", at)
+	case KindMissingQuotes:
+		return fmt.Sprintf("The text at %s was not quoted; it was treated as a string.", at)
+	case KindSingleQuotes:
+		return fmt.Sprintf("The string at %s used single quotes; they were replaced with double quotes.", at)
+	case KindBacktickQuotes:
+		return fmt.Sprintf("The string at %s used backticks; they were replaced with double quotes.", at)
+	case KindTripleQuotes:
+		return fmt.Sprintf("The string at %s used triple quotes; its content was kept verbatim.", at)
+	case KindMissingColon:
+		return fmt.Sprintf("The object key before %s was not followed by a colon; a `:` was inserted.", at)
+	case KindMissingComma:
+		return fmt.Sprintf("A comma was missing at %s; a `,` was inserted.", at)
+	case KindExtraComma:
+		return fmt.Sprintf("The comma at %s was not needed and was removed.", at)
+	case KindSemicolonSeparator:
+		return fmt.Sprintf("A semicolon was used as a separator at %s; it was replaced with `,`.", at)
+	case KindNewlineSeparator:
+		return fmt.Sprintf("Values were separated only by a newline at %s; a `,` was inserted.", at)
+	case KindDecimalComma:
+		return fmt.Sprintf("The number at %s used a decimal comma; it was converted to a decimal point.", at)
+	case KindUnitSuffix:
+		return fmt.Sprintf("The number at %s had a unit suffix.", at)
+	case KindTruncatedNumber:
+		return fmt.Sprintf("The number at %s was cut off at the end of the input and was completed.", at)
+	case KindInvalidNumber:
+		return fmt.Sprintf("The number at %s was malformed; it was kept as a string.", at)
+	case KindSetLiteral:
+		return fmt.Sprintf("The braces at %s enclosed values without keys; they were treated as an array.", at)
+	case KindCallExpression:
+		return fmt.Sprintf("The value at %s was a constructor expression; it was kept verbatim as a string.", at)
+	case KindTranscoded:
+		return "The input was UTF-16 encoded; it was converted to UTF-8."
+	case KindInvalidUTF8:
+		return fmt.Sprintf("The input contained invalid UTF-8 bytes starting at %s; they were cleaned up so the output is valid UTF-8.", at)
+	case KindNormalizedKey:
+		return fmt.Sprintf("The key at %s was a duplicate after Unicode normalization; %s.", at, d.Message)
+	case KindKeyCase:
+		return fmt.Sprintf("The key at %s did not match the requested naming convention; %s.", at, d.Message)
+	case KindSanitizedKey:
+		return fmt.Sprintf("The key at %s was not a valid identifier; %s.", at, d.Message)
+	case KindUnflattenedKeys:
+		return fmt.Sprintf("The object at %s used dot-notation keys; they were expanded into nested values.", at)
+	case KindSparseFlatKey:
+		return fmt.Sprintf("A dot-notation key at %s used an array index too large to expand; it was kept flattened.", at)
+	case KindUnitMemberRenamed:
+		return fmt.Sprintf("The unit split from the number near %s collided with an existing member and was written under a suffixed key.", at)
+	case KindCustomRule:
+		return fmt.Sprintf("At %s, %s.", at, d.Message)
+	case KindShapeCoerced:
+		return fmt.Sprintf("The value at %s did not match the skeleton; %s.", at, d.Message)
+	case KindTemplatePlaceholder:
+		return fmt.Sprintf("The template placeholder at %s was kept as a string.", at)
+	case KindFullWidthPunctuation:
+		return fmt.Sprintf("Full-width punctuation starting at %s was replaced with ASCII (%s).", at, d.Message)
+	case KindXMLTag:
+		return fmt.Sprintf("The JSON was extracted from the XML tag at %s.", at)
+	case KindFlagKey:
+		return fmt.Sprintf("The object key before %s had no value; it was treated as a flag and set to true.", at)
+	case KindKeyValuePairs:
+		return fmt.Sprintf("The input starting at %s was key=value text; it was converted into an object.", at)
+	case KindComment:
+		return fmt.Sprintf("The comment at %s was removed.", at)
+	case KindUnparseableValue:
+		return fmt.Sprintf("The %s at %s.", d.Message, at)
+	case KindWrappedScalar:
+		return fmt.Sprintf("The top-level value was not an object; the %s.", d.Message)
+	case KindTruncatedArray:
+		return fmt.Sprintf("The array at %s was too long; %s.", at, d.Message)
+	case KindTruncatedString:
+		return fmt.Sprintf("The string at %s was too long and was truncated.", at)
+	case KindMultipleDocuments:
+		return fmt.Sprintf("Another top-level value started at %s; all values were collected into an array.", at)
+	}
+	return fmt.Sprintf("%s at %s.", d.Message, at)
+}
@@ -0,0 +1,187 @@
+package pkg
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// RepairFile 读取文件并修复其中的 JSON，通过临时文件加重命名的方式原子地写回
+// 使用 WithWriteAlongside 时结果写入同目录下的 <name>.repaired.json；
+// 使用 WithStreamingInput 时分块读取文件并以 RepairStream 的方式逐个记号写出，不会将整个文件读入内存；
+// 此时与其余按值处理的选项同时使用会返回错误
+func RepairFile(path string, opts ...Option) error {
+	p := NewParser("", opts...)
+	target := path
+	if p.writeAlongside {
+		target = repairedPath(path)
+	}
+	if p.streamingInput {
+		if err := p.setupErr(); err != nil {
+			return err
+		}
+		if names := p.streamingConflicts(); len(names) > 0 {
+			return fmt.Errorf("WithStreamingInput cannot be combined with %s", strings.Join(names, ", "))
+		}
+		return repairFileStream(path, target)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	p.Reset(string(data))
+	repaired, err := p.repairBytes()
+	if err != nil {
+		return fmt.Errorf("failed to repair %s: %w", path, err)
+	}
+	return writeFileAtomic(target, path, func(w io.Writer) error {
+		_, err := w.Write(repaired)
+		return err
+	})
+}
+
+// streamingConflicts 返回已配置但在 WithStreamingInput 模式下无法生效的选项，
+// 静默忽略它们会导致例如 WithRedactKeys 要隐藏的值被原样写入文件
+func (p *Parser) streamingConflicts() []string {
+	var names []string
+	for _, option := range []struct {
+		name string
+		set  bool
+	}{
+		{"WithCache", p.cache != nil},
+		{"WithExplanation", p.explanation != nil},
+		{"WithStats", p.stats != nil},
+		{"WithMaxStringLength", p.maxStringLength > 0},
+		{"WithCharset", p.charset != nil},
+		{"WithInvalidUTF8", p.invalidUTF8 != InvalidUTF8Replace},
+		{"WithSanitizedKeys", p.sanitizeKeys},
+		{"WithKeyCase", p.keyCase != KeyCaseKeep},
+		{"WithUnflatten", p.unflatten},
+		{"WithRepairRule", len(p.rules) > 0},
+		{"WithSkeleton", p.skeleton != nil},
+		{"WithTransform", len(p.transforms) > 0},
+		{"WithUnicodeNormalization", p.normalize},
+		{"WithRedactKeys", len(p.redactKeys) > 0},
+		{"WithIncludeKeys", len(p.includeKeys) > 0},
+		{"WithExcludeKeys", len(p.excludeKeys) > 0},
+		{"WithMaxElements", p.maxElements > 0},
+		{"WithMaxArrayElements", p.maxArrayLength > 0},
+		{"WithStrictCheck", p.strictCheck},
+		{"WithMaxOutputBytes", p.maxOutputBytes > 0},
+		{"WithDecimalComma", p.decimalComma},
+		{"WithTruncatedNumbers", p.truncatedNumbers != TruncatedNumberTrim},
+		{"WithTopLevelScalar", p.scalarPolicy != ScalarAllow},
+		{"WithUnits", p.units != UnitsIgnore},
+		{"WithTemplatePlaceholders", p.templates},
+		{"WithFullWidthPunctuation", p.fullWidth},
+		{"WithXMLTags", p.xmlTags},
+		{"WithFlagKeys", p.flagKeys},
+		{"WithKeyValuePairs", p.keyValuePairs},
+		{"WithComments", p.comments},
+		{"WithCommentCapture", p.commentsOut != nil},
+		{"WithUnparseableValue", p.unparseable.mode != unparseableEmpty},
+		{"WithProgress", p.progress != nil},
+		{"WithGarbageStrategy", p.garbageStrategy != SkipCharacter},
+		{"WithMaxGarbage", p.maxGarbage > 0},
+	} {
+		if option.set {
+			names = append(names, option.name)
+		}
+	}
+	return names
+}
+
+// repairFileStream 以流的方式修复 path 并写入 target，内存占用与文件大小无关
+func repairFileStream(path, target string) error {
+	src, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	defer src.Close()
+
+	return writeFileAtomic(target, path, func(w io.Writer) error {
+		buf := bufio.NewWriter(w)
+		if err := RepairStream(src, buf); err != nil {
+			return fmt.Errorf("failed to repair %s: %w", path, err)
+		}
+		return buf.Flush()
+	})
+}
+
+// repairedPath 返回与 path 同目录的 .repaired.json 文件路径
+func repairedPath(path string) string {
+	return strings.TrimSuffix(path, filepath.Ext(path)) + ".repaired.json"
+}
+
+// writeFileAtomic 通过 write 写入同目录的临时文件再重命名，保证目标文件不会处于写了一半的状态
+// 新文件的权限沿用 permFrom 指向的文件
+func writeFileAtomic(path, permFrom string, write func(w io.Writer) error) error {
+	mode := os.FileMode(0o644)
+	if info, err := os.Stat(permFrom); err == nil {
+		mode = info.Mode().Perm()
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".tmp*")
+	if err != nil {
+		return fmt.Errorf("failed to create temp file: %w", err)
+	}
+	tmpName := tmp.Name()
+	defer os.Remove(tmpName)
+
+	if err := write(tmp); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write temp file: %w", err)
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to sync temp file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to close temp file: %w", err)
+	}
+	if err := os.Chmod(tmpName, mode); err != nil {
+		return fmt.Errorf("failed to set file mode: %w", err)
+	}
+	if err := os.Rename(tmpName, path); err != nil {
+		return fmt.Errorf("failed to replace %s: %w", path, err)
+	}
+	return nil
+}
+
+// FileResult 是 RepairFS 中单个文件的修复结果
+type FileResult struct {
+	Path     string
+	Repaired string
+	Err      error
+}
+
+// RepairFS 使用 workers 个并发协程修复 fsys 中所有匹配 glob（fs.Glob 语法）的文件
+// 结果顺序与匹配到的文件顺序一致，单个文件的错误记录在对应结果中
+func RepairFS(fsys fs.FS, glob string, workers int, opts ...Option) ([]FileResult, error) {
+	paths, err := fs.Glob(fsys, glob)
+	if err != nil {
+		return nil, fmt.Errorf("invalid glob %q: %w", glob, err)
+	}
+	results := make([]FileResult, len(paths))
+	runWorkers(len(paths), workers, func() func(i int) {
+		return func(i int) {
+			results[i] = repairFSFile(fsys, paths[i], opts)
+		}
+	})
+	return results, nil
+}
+
+// repairFSFile 读取并修复 fsys 中的单个文件
+func repairFSFile(fsys fs.FS, path string, opts []Option) FileResult {
+	data, err := fs.ReadFile(fsys, path)
+	if err != nil {
+		return FileResult{Path: path, Err: fmt.Errorf("failed to read %s: %w", path, err)}
+	}
+	repaired, err := Repair(string(data), opts...)
+	return FileResult{Path: path, Repaired: repaired, Err: err}
+}
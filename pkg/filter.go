@@ -0,0 +1,123 @@
+package pkg
+
+import "strings"
+
+// 修复时 parseObject 与 parseSequence 在解析过程中跳过不需要的成员和元素，不为它们构建值；
+// 本身合法、走标准库快速路径的输入则在解码后由 filterValue 删除
+
+// enterPath 进入当前值的子值 token，返回恢复路径的函数以及该子值是否需要保留
+func (p *Parser) enterPath(token pathToken) (func(), bool) {
+	if !p.filtered {
+		return func() {}, true
+	}
+	tokens, included := p.filterTokens, p.filterIncluded
+	childTokens := append(tokens[:len(tokens):len(tokens)], token)
+	keep, childIncluded := p.keepPath(childTokens, included)
+	p.filterTokens, p.filterIncluded = childTokens, childIncluded
+	return func() { p.filterTokens, p.filterIncluded = tokens, included }, keep
+}
+
+// skipValue 跳过当前位置的一个值而不构建它，用于被筛选掉的成员和超出 WithMaxArrayElements 的元素：容器跳到匹配的右括号，字符串跳到结束引号，
+// 其余内容跳到逗号、右括号或换行；被跳过部分中的问题不会记录为诊断信息
+// 当前位置是多余的右括号等不能开始一个值的字符时只跳过这一个字符并返回 false，保证调用方的循环总能前进
+func (p *Parser) skipValue() bool {
+	char, ok := p.getChar(0)
+	if !ok {
+		return false
+	}
+	if char == '}' || char == ']' || char == ',' || char == '\n' {
+		p.index++
+		return false
+	}
+	if char != '{' && char != '[' && char != '"' && char != '\'' && char != '`' {
+		stops := ",}]\n"
+		if char == '-' || ('0' <= char && char <= '9') {
+			// 数字在空白处结束，使 [1 2 3] 按缺少逗号处理
+			stops += " \t\r"
+		}
+		p.index++
+		for p.index < len(p.jsonStr) && !strings.ContainsRune(stops, p.jsonStr[p.index]) {
+			p.index++
+		}
+		return true
+	}
+
+	depth := 0
+	var quote rune
+	for ; p.index < len(p.jsonStr); p.index++ {
+		c := p.jsonStr[p.index]
+		if quote != 0 {
+			if c == '\\' {
+				p.index++
+			} else if c == quote {
+				quote = 0
+				if depth == 0 {
+					p.index++
+					return true
+				}
+			}
+			continue
+		}
+		switch c {
+		case '"', '\'', '`':
+			quote = c
+		case '{', '[':
+			depth++
+		case '}', ']':
+			depth--
+			if depth == 0 {
+				p.index++
+				return true
+			}
+		}
+	}
+	return true
+}
+
+// filterValue 按 WithIncludeKeys 与 WithExcludeKeys 删除对象成员和数组元素
+// included 表示祖先路径已经被包含规则完整匹配，此时其后代全部保留
+func (p *Parser) filterValue(value interface{}, tokens []pathToken, included bool) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, child := range v {
+			childTokens := append(tokens[:len(tokens):len(tokens)], pathToken{key: key})
+			keep, childIncluded := p.keepPath(childTokens, included)
+			if !keep {
+				delete(v, key)
+				continue
+			}
+			v[key] = p.filterValue(child, childTokens, childIncluded)
+		}
+	case []interface{}:
+		out := v[:0]
+		for i, child := range v {
+			childTokens := append(tokens[:len(tokens):len(tokens)], pathToken{index: i, isIndex: true})
+			if keep, childIncluded := p.keepPath(childTokens, included); keep {
+				out = append(out, p.filterValue(child, childTokens, childIncluded))
+			}
+		}
+		return out
+	}
+	return value
+}
+
+// keepPath 判断路径是否保留，并返回其后代是否已被包含规则完整覆盖
+func (p *Parser) keepPath(tokens []pathToken, included bool) (bool, bool) {
+	for _, pattern := range p.excludeKeys {
+		if pattern.matchTokens(tokens) {
+			return false, false
+		}
+	}
+	if included || len(p.includeKeys) == 0 {
+		return true, included
+	}
+	keep := false
+	for _, pattern := range p.includeKeys {
+		if pattern.matchTokens(tokens) {
+			return true, true
+		}
+		// 路径是某条包含规则的前缀时保留，继续在子值中筛选
+		keep = keep || pattern.matchPrefix(tokens)
+	}
+	return keep, false
+}
@@ -0,0 +1,196 @@
+package pkg
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Flatten 修复JSON并将结果展开为以点号路径为键的单层映射，
+// 例如 {"user.name": "Alice", "user.orgs[0]": "Org1"}
+// 空对象和空数组会作为值保留，顶层为标量时使用空字符串作为键
+func Flatten(jsonStr string, opts ...Option) (map[string]interface{}, error) {
+	value, err := Loads(jsonStr, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make(map[string]interface{})
+	flattenValue(out, "", value)
+	return out, nil
+}
+
+// flattenValue 将 value 展开写入 out，prefix 为当前路径
+func flattenValue(out map[string]interface{}, prefix string, value interface{}) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		if len(v) == 0 {
+			out[prefix] = v
+			return
+		}
+		for key, child := range v {
+			if prefix == "" {
+				flattenValue(out, key, child)
+			} else {
+				flattenValue(out, prefix+"."+key, child)
+			}
+		}
+	case []interface{}:
+		if len(v) == 0 {
+			out[prefix] = v
+			return
+		}
+		for i, child := range v {
+			flattenValue(out, prefix+"["+strconv.Itoa(i)+"]", child)
+		}
+	default:
+		out[prefix] = v
+	}
+}
+
+// pathSegment 是展开路径中的一段：对象键或数组下标
+type pathSegment struct {
+	key     string
+	index   int
+	isIndex bool
+}
+
+// parseFlatKey 解析 "user.orgs[0]" 形式的键，不是展开路径时返回 false
+func parseFlatKey(key string) ([]pathSegment, bool) {
+	if !strings.ContainsAny(key, ".[") {
+		return nil, false
+	}
+	segments := make([]pathSegment, 0)
+	for _, part := range strings.Split(key, ".") {
+		name, rest, hasIndex := strings.Cut(part, "[")
+		// 空段与以下标开头的路径（例如 "x."、"[0].a"）不视为展开路径
+		if name == "" {
+			return nil, false
+		}
+		segments = append(segments, pathSegment{key: name})
+		if !hasIndex {
+			continue
+		}
+		for _, idx := range strings.Split(strings.TrimSuffix("["+rest, "]"), "]") {
+			n, err := strconv.Atoi(strings.TrimPrefix(idx, "["))
+			if !strings.HasPrefix(idx, "[") || err != nil || n < 0 {
+				return nil, false
+			}
+			segments = append(segments, pathSegment{index: n, isIndex: true})
+		}
+	}
+	return segments, len(segments) > 1
+}
+
+// flatIndexSlack 是路径键中始终允许的数组下标上限，使少量缺失的元素仍能还原为带 null 的数组
+const flatIndexSlack = 1024
+
+// unflattenObject 将对象中的点号路径键还原为嵌套结构，路径之间冲突的键保持原样
+// 数组下标同时不小于对象键数量与 flatIndexSlack 的路径键同样保持原样并通过 sparse 返回，
+// 避免 "x[99999999999]" 这样的键分配巨大的数组
+func unflattenObject(obj map[string]interface{}) (result map[string]interface{}, changed bool, sparse []string) {
+	keys := make([]string, 0, len(obj))
+	for key := range obj {
+		keys = append(keys, key)
+	}
+	// 先放入普通键，再按字典序放入路径键，保证结果稳定
+	sort.Slice(keys, func(i, j int) bool {
+		_, fi := parseFlatKey(keys[i])
+		_, fj := parseFlatKey(keys[j])
+		if fi != fj {
+			return !fi
+		}
+		return keys[i] < keys[j]
+	})
+
+	var root interface{} = make(map[string]interface{}, len(obj))
+	for _, key := range keys {
+		segments, ok := parseFlatKey(key)
+		if !ok {
+			root.(map[string]interface{})[key] = obj[key]
+			continue
+		}
+		if maxIndex(segments) >= max(len(obj), flatIndexSlack) {
+			sparse = append(sparse, key)
+			root.(map[string]interface{})[key] = obj[key]
+			continue
+		}
+		if updated, ok := insertPath(root, segments, obj[key]); ok {
+			root = updated
+			changed = true
+		} else {
+			root.(map[string]interface{})[key] = obj[key]
+		}
+	}
+	return root.(map[string]interface{}), changed, sparse
+}
+
+// maxIndex 返回路径中最大的数组下标，没有下标时返回 -1
+func maxIndex(segments []pathSegment) int {
+	n := -1
+	for _, seg := range segments {
+		if seg.isIndex {
+			n = max(n, seg.index)
+		}
+	}
+	return n
+}
+
+// insertPath 沿路径将 value 写入容器，返回更新后的容器；路径与已有值类型冲突时返回 false
+func insertPath(container interface{}, segments []pathSegment, value interface{}) (interface{}, bool) {
+	seg := segments[0]
+	if seg.isIndex {
+		arr, ok := container.([]interface{})
+		if container == nil {
+			arr, ok = make([]interface{}, 0), true
+		}
+		if !ok {
+			return container, false
+		}
+		for len(arr) <= seg.index {
+			arr = append(arr, nil)
+		}
+		if len(segments) == 1 {
+			if arr[seg.index] != nil {
+				return container, false
+			}
+			arr[seg.index] = value
+			return arr, true
+		}
+		child, ok := insertPath(arr[seg.index], segments[1:], value)
+		if !ok {
+			return container, false
+		}
+		arr[seg.index] = child
+		return arr, true
+	}
+
+	obj, ok := container.(map[string]interface{})
+	if container == nil {
+		obj, ok = make(map[string]interface{}), true
+	}
+	if !ok {
+		return container, false
+	}
+	existing, exists := obj[seg.key]
+	if len(segments) == 1 {
+		if exists {
+			return container, false
+		}
+		obj[seg.key] = value
+		return obj, true
+	}
+	if exists {
+		switch existing.(type) {
+		case map[string]interface{}, []interface{}:
+		default:
+			return container, false
+		}
+	}
+	child, ok := insertPath(existing, segments[1:], value)
+	if !ok {
+		return container, false
+	}
+	obj[seg.key] = child
+	return obj, true
+}
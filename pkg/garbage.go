@@ -0,0 +1,53 @@
+package pkg
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// GarbageStrategy 决定解析器遇到无法识别的内容时跳过多少字符
+type GarbageStrategy int
+
+const (
+	// SkipCharacter 每次跳过一个字符后重试（默认）
+	SkipCharacter GarbageStrategy = iota
+	// SkipToStructural 跳到下一个结构字符（{ } [ ] , : 或引号）
+	SkipToStructural
+	// SkipToNextLine 跳到下一行的开头，适合夹杂整段说明文字的输入
+	SkipToNextLine
+)
+
+// structuralChars 是 SkipToStructural 停下的字符
+const structuralChars = "{}[],:\"'"
+
+// ErrTooMuchGarbage 表示跳过的内容超过了 WithMaxGarbage 设置的上限
+var ErrTooMuchGarbage = errors.New("input contains too much unparseable text")
+
+// errNoValue 表示值的位置上只有被跳过的内容
+var errNoValue = errors.New("no value before separator")
+
+// skipGarbage 按 WithGarbageStrategy 跳过当前位置的无法识别的内容并记录诊断
+// 累计跳过的字符数超过 WithMaxGarbage 时停止解析
+func (p *Parser) skipGarbage() {
+	start := p.index
+	p.index++
+	switch p.garbageStrategy {
+	case SkipToStructural:
+		for p.index < len(p.jsonStr) && !strings.ContainsRune(structuralChars, p.jsonStr[p.index]) {
+			p.index++
+		}
+	case SkipToNextLine:
+		for p.index < len(p.jsonStr) && p.jsonStr[p.index-1] != '\n' {
+			p.index++
+		}
+	}
+	p.addDiagnostic(KindSkippedGarbage, SeverityError, start, p.index-start,
+		"skipped unexpected characters", "remove the characters")
+
+	p.skipped += p.index - start
+	if p.maxGarbage > 0 && p.skipped > p.maxGarbage && p.garbageErr == nil {
+		p.garbageErr = fmt.Errorf("%w: skipped more than %d characters", ErrTooMuchGarbage, p.maxGarbage)
+		p.index = len(p.jsonStr)
+	}
+}
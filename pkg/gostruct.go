@@ -0,0 +1,179 @@
+package pkg
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// GenerateGoStructs 修复每个样本并合并它们的结构，生成带 json 标签的 Go 结构体定义（不含 package 子句）
+// 根类型命名为 typeName，嵌套对象的类型名由所在类型名与字段名拼接而成；
+// 并非在每个样本中都出现的键会加上 omitempty，可能为 null 的标量使用指针类型
+func GenerateGoStructs(typeName string, samples []string, opts ...Option) ([]byte, error) {
+	if len(samples) == 0 {
+		return nil, fmt.Errorf("at least one sample is required")
+	}
+	shape := newShapeNode()
+	for i, sample := range samples {
+		value, err := Loads(sample, opts...)
+		if err != nil {
+			return nil, fmt.Errorf("sample %d: %w", i, err)
+		}
+		shape.add(value)
+	}
+
+	g := &structGenerator{names: make(map[string]bool)}
+	var out bytes.Buffer
+	if types := shape.typeNames(); len(types) == 1 && types[0] == "object" {
+		g.structType(typeName, shape)
+	} else {
+		// 根值不是对象时定义同名的类型，便于调用方直接引用
+		g.names[typeName] = true
+		fmt.Fprintf(&out, "type %s %s\n\n", typeName, strings.TrimPrefix(g.goType(typeName, shape), "*"))
+	}
+	out.Write(g.buf.Bytes())
+	src, err := format.Source(out.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("failed to format generated code: %w", err)
+	}
+	return src, nil
+}
+
+// structGenerator 按深度优先顺序输出结构体定义
+type structGenerator struct {
+	buf   bytes.Buffer
+	names map[string]bool
+}
+
+// goType 返回结构对应的 Go 类型，遇到对象时生成结构体定义
+func (g *structGenerator) goType(name string, n *shapeNode) string {
+	types := n.typeNames()
+	nullable := n.types["null"]
+	nonNull := make([]string, 0, len(types))
+	for _, t := range types {
+		if t != "null" {
+			nonNull = append(nonNull, t)
+		}
+	}
+	if len(nonNull) != 1 {
+		return "interface{}"
+	}
+
+	var goType string
+	switch nonNull[0] {
+	case "object":
+		return "*" + g.structType(name, n)
+	case "array":
+		if n.items == nil || len(n.items.types) == 0 {
+			return "[]interface{}"
+		}
+		return "[]" + strings.TrimPrefix(g.goType(itemName(name), n.items), "*")
+	case "string":
+		goType = "string"
+	case "integer":
+		goType = "int64"
+	case "number":
+		goType = "float64"
+	case "boolean":
+		goType = "bool"
+	}
+	if nullable {
+		return "*" + goType
+	}
+	return goType
+}
+
+// structType 输出对象对应的结构体定义并返回类型名
+func (g *structGenerator) structType(name string, n *shapeNode) string {
+	name = g.uniqueName(name)
+	keys := make([]string, 0, len(n.properties))
+	for key := range n.properties {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	// 先确定所有字段再输出，使嵌套类型排在外层类型之后
+	var fields strings.Builder
+	fieldNames := make(map[string]bool, len(keys))
+	required := make(map[string]bool)
+	for _, key := range n.required() {
+		required[key] = true
+	}
+	type field struct{ name, key string }
+	ordered := make([]field, 0, len(keys))
+	for _, key := range keys {
+		fieldName := goIdentifier(key)
+		for i := 2; fieldNames[fieldName]; i++ {
+			fieldName = fmt.Sprintf("%s%d", goIdentifier(key), i)
+		}
+		fieldNames[fieldName] = true
+		ordered = append(ordered, field{fieldName, key})
+	}
+
+	var nested structGenerator
+	nested.names = g.names
+	for _, f := range ordered {
+		tag := f.key
+		if !required[f.key] {
+			tag += ",omitempty"
+		}
+		fmt.Fprintf(&fields, "\t%s %s `json:%q`\n", f.name, nested.goType(name+f.name, n.properties[f.key]), tag)
+	}
+
+	fmt.Fprintf(&g.buf, "type %s struct {\n%s}\n\n", name, fields.String())
+	g.buf.Write(nested.buf.Bytes())
+	return name
+}
+
+// itemName 返回数组元素的类型名，复数形式的名称去掉结尾的 s，例如 Items 对应 Item
+func itemName(name string) string {
+	if singular := strings.TrimSuffix(name, "s"); singular != name && singular != "" {
+		return singular
+	}
+	return name + "Item"
+}
+
+// uniqueName 保证生成的类型名不重复
+func (g *structGenerator) uniqueName(name string) string {
+	unique := name
+	for i := 2; g.names[unique]; i++ {
+		unique = fmt.Sprintf("%s%d", name, i)
+	}
+	g.names[unique] = true
+	return unique
+}
+
+// commonInitialisms 是生成字段名时整体大写的常见缩写
+var commonInitialisms = map[string]bool{
+	"API": true, "HTML": true, "HTTP": true, "ID": true, "IP": true, "JSON": true,
+	"SQL": true, "UI": true, "URI": true, "URL": true, "UUID": true, "XML": true,
+}
+
+// goIdentifier 将 JSON 键转换为导出的 Go 标识符，例如 user_id 转换为 UserID，姓名转换为 X姓名
+func goIdentifier(key string) string {
+	parts := strings.FieldsFunc(key, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	var sb strings.Builder
+	for _, part := range parts {
+		if upper := strings.ToUpper(part); commonInitialisms[upper] {
+			sb.WriteString(upper)
+			continue
+		}
+		runes := []rune(part)
+		runes[0] = unicode.ToUpper(runes[0])
+		sb.WriteString(string(runes))
+	}
+	name := sb.String()
+	switch {
+	case name == "" || !unicode.IsLetter([]rune(name)[0]):
+		name = "Field" + name
+	case !unicode.IsUpper([]rune(name)[0]):
+		// 中文等没有大小写的字母开头的字段不会被导出，encoding/json 会忽略它
+		name = "X" + name
+	}
+	return name
+}
@@ -1,45 +1,238 @@
 package pkg
 
 import (
+	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"unicode/utf8"
 )
 
 // Repair 尝试修复并解析JSON字符串
-func Repair(jsonStr string) (string, error) {
-	// 尝试直接解析，如果成功就直接返回
-	var out interface{}
-	if err := json.Unmarshal([]byte(jsonStr), &out); err == nil {
-		repaired, err := json.MarshalIndent(out, "", "  ")
-		if err != nil {
-			return "", fmt.Errorf("failed to re-marshal already-valid json: %w", err)
-		}
-		return string(repaired), nil
-	}
+func Repair(jsonStr string, opts ...Option) (string, error) {
+	return NewParser(jsonStr, opts...).repair()
+}
 
-	// 如果直接解析失败，则启动修复程序
-	parser := NewParser(jsonStr)
-	parsedJSON, err := parser.Parse()
+// repair 修复输入并返回格式化的 JSON 字符串
+func (p *Parser) repair() (string, error) {
+	repaired, err := p.repairBytes()
 	if err != nil {
 		return "", err
 	}
+	return string(repaired), nil
+}
+
+// repairBytes 修复输入并返回格式化的 JSON
+func (p *Parser) repairBytes() ([]byte, error) {
+	parsedJSON, err := p.load()
+	if err != nil {
+		return nil, err
+	}
 
 	repaired, err := json.MarshalIndent(parsedJSON, "", "  ")
 	if err != nil {
-		return "", fmt.Errorf("failed to marshal repaired json: %w", err)
+		return nil, fmt.Errorf("failed to marshal repaired json: %w", err)
+	}
+	if p.strictCheck {
+		if err := checkRFC8259(repaired); err != nil {
+			return nil, err
+		}
+	}
+	return repaired, nil
+}
+
+// RepairBytes 与 Repair 相同，但直接接收和返回字节切片，省去结果的字符串转换；
+// 输入本身是合法 JSON 时也不会复制 data
+func RepairBytes(data []byte, opts ...Option) ([]byte, error) {
+	return newBytesParser(data, opts...).repairBytes()
+}
+
+// newBytesParser 与 NewParser 相同，但输入无需转码时保留调用方的 data：
+// 合法 JSON 直接交给 json.Unmarshal，只在需要修复时才转换为字符串
+func newBytesParser(data []byte, opts ...Option) *Parser {
+	p := NewParser("", opts...)
+	if p.charset != nil || !utf8.Valid(data) || bytes.HasPrefix(data, []byte(utf8BOM)) || bytes.IndexByte(data, 0) >= 0 {
+		// 需要 decodeInput 处理字符集、BOM 或 UTF-16
+		p.Reset(string(data))
+		return p
+	}
+	p.rawBytes = data
+	return p
+}
+
+// inputBytes 返回用于快速路径与缓存键的输入字节
+func (p *Parser) inputBytes() []byte {
+	if p.rawBytes != nil {
+		return p.rawBytes
+	}
+	return []byte(p.raw)
+}
+
+// materialize 将保留的输入字节转换为 raw 与 jsonStr，供修复以及依赖输入文本的统计使用
+func (p *Parser) materialize() {
+	if p.rawBytes == nil {
+		return
+	}
+	p.raw, p.rawBytes = string(p.rawBytes), nil
+	p.jsonStr = p.jsonStr[:0]
+	for _, r := range p.raw {
+		p.jsonStr = append(p.jsonStr, r)
 	}
-	return string(repaired), nil
 }
 
 // Loads 修复JSON并返回一个数据结构 (map[string]interface{} 或 []interface{})
-func Loads(jsonStr string) (interface{}, error) {
-	// 尝试直接解析
+func Loads(jsonStr string, opts ...Option) (interface{}, error) {
+	return NewParser(jsonStr, opts...).load()
+}
+
+// load 返回修复结果，启用缓存时优先从缓存读取
+func (p *Parser) load() (interface{}, error) {
+	if err := p.setupErr(); err != nil {
+		return nil, err
+	}
+	if p.cache != nil {
+		return p.loadCached()
+	}
+	return p.loadUncached()
+}
+
+// loadUncached 尝试直接解析，如果失败则启动修复程序
+func (p *Parser) loadUncached() (interface{}, error) {
 	var out interface{}
-	if err := json.Unmarshal([]byte(jsonStr), &out); err == nil {
-		return out, nil
+	var err error
+	if p.maxArrayLength > 0 && len(p.includeKeys) == 0 && len(p.excludeKeys) == 0 {
+		// 按下标筛选需要看到截断前的数组，此时仍由 json.Unmarshal 解码后再截断
+		out, err = p.decodeLimited(p.inputBytes())
+	} else {
+		err = json.Unmarshal(p.inputBytes(), &out)
+	}
+	if err == nil {
+		out, err = p.postProcess(out)
+		p.finish()
+		return out, err
+	}
+	p.materialize()
+	p.fallback = true
+	return p.Parse()
+}
+
+// decodeLimited 与 json.Unmarshal 相同地解码合法 JSON，但数组中超出 WithMaxArrayElements 的元素只校验不构建
+func (p *Parser) decodeLimited(data []byte) (interface{}, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	value, err := p.decodeToken(dec, "$")
+	if err != nil {
+		return nil, err
+	}
+	if _, err := dec.Token(); !errors.Is(err, io.EOF) {
+		return nil, errors.New("unexpected content after top-level value")
+	}
+	return value, nil
+}
+
+// decodeToken 从 dec 读取一个完整的值，path 为该值的路径
+func (p *Parser) decodeToken(dec *json.Decoder, path string) (interface{}, error) {
+	token, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+	delim, ok := token.(json.Delim)
+	if !ok {
+		return token, nil
 	}
 
-	// 如果失败则修复
-	parser := NewParser(jsonStr)
-	return parser.Parse()
+	var value interface{}
+	if delim == '{' {
+		obj := make(map[string]interface{})
+		for dec.More() {
+			token, err := dec.Token()
+			if err != nil {
+				return nil, err
+			}
+			key, _ := token.(string)
+			if obj[key], err = p.decodeToken(dec, childPath(path, key)); err != nil {
+				return nil, err
+			}
+		}
+		value = obj
+	} else {
+		arr := make([]interface{}, 0)
+		dropped := 0
+		for i := 0; dec.More(); i++ {
+			if len(arr) >= p.maxArrayLength {
+				if err := skipToken(dec); err != nil {
+					return nil, err
+				}
+				dropped++
+				continue
+			}
+			elem, err := p.decodeToken(dec, indexPath(path, i))
+			if err != nil {
+				return nil, err
+			}
+			arr = append(arr, elem)
+		}
+		if dropped > 0 {
+			p.addTruncatedArray(path, dropped)
+		}
+		value = arr
+	}
+	// 读取结束的括号
+	if _, err := dec.Token(); err != nil {
+		return nil, err
+	}
+	return value, nil
+}
+
+// skipToken 从 dec 读取并丢弃一个完整的值
+func skipToken(dec *json.Decoder) error {
+	depth := 0
+	for {
+		token, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		switch token {
+		case json.Delim('{'), json.Delim('['):
+			depth++
+		case json.Delim('}'), json.Delim(']'):
+			depth--
+		}
+		if depth == 0 {
+			return nil
+		}
+	}
+}
+
+// LoadsBytes 与 Loads 相同，但直接接收字节切片，便于处理 HTTP 请求体等输入；
+// 输入本身是合法 JSON 时不会复制 data
+func LoadsBytes(data []byte, opts ...Option) (interface{}, error) {
+	return newBytesParser(data, opts...).load()
+}
+
+// LoadsRepaired 与 Loads 相同，并额外返回输入是否经过了修复（而非本身就是合法 JSON）
+func LoadsRepaired(jsonStr string, opts ...Option) (interface{}, bool, error) {
+	p := NewParser(jsonStr, opts...)
+	value, err := p.load()
+	return value, p.WasRepaired(), err
+}
+
+// Load 修复当前输入并返回解析后的数据结构，与 Loads 的流程相同
+// 结束后可以从同一个解析器读取 Diagnostics、Stats 与 WasRepaired，它们与返回值对应同一次修复
+func (p *Parser) Load() (interface{}, error) {
+	return p.load()
+}
+
+// WasRepaired 报告本次解析是否对输入做了任何修改
+func (p *Parser) WasRepaired() bool {
+	return p.fallback || len(p.diagnostics) > 0
+}
+
+// LoadsReader 从 io.Reader 读取内容，修复并返回解析后的数据结构
+func LoadsReader(r io.Reader, opts ...Option) (interface{}, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read input: %w", err)
+	}
+	return LoadsBytes(data, opts...)
 }
@@ -0,0 +1,53 @@
+//go:build goexperiment.jsonv2 && go1.27
+
+package pkg
+
+import (
+	"encoding/json"
+	"encoding/json/jsontext"
+	"fmt"
+	"io"
+)
+
+// NewJSONTextDecoder 返回一个 jsontext.Decoder，它读取的是 r 中每个顶层文档修复后的结果
+// 文档按需逐个修复，迁移到 encoding/json/v2 的代码可以直接在其上调用 ReadToken、ReadValue 或 json.UnmarshalDecode
+// 修复在调用方的协程中进行，不必读完解码器也不会遗留后台协程
+func NewJSONTextDecoder(r io.Reader, opts ...Option) *jsontext.Decoder {
+	r, opts = decodeStream(r, opts)
+	return jsontext.NewDecoder(&repairedReader{scanner: newDocumentScanner(r), opts: opts})
+}
+
+// repairedReader 与 Values 一样逐个切分并修复顶层文档，将结果编码为以换行分隔的 JSON 文本
+type repairedReader struct {
+	scanner *documentScanner
+	opts    []Option
+	buf     []byte
+	err     error
+}
+
+func (r *repairedReader) Read(b []byte) (int, error) {
+	for len(r.buf) == 0 {
+		if r.err != nil {
+			return 0, r.err
+		}
+		doc, err := r.scanner.next()
+		if err != nil {
+			r.err = err
+			continue
+		}
+		value, err := Loads(doc, r.opts...)
+		if err != nil {
+			r.err = err
+			continue
+		}
+		data, err := json.Marshal(value)
+		if err != nil {
+			r.err = fmt.Errorf("failed to marshal repaired json: %w", err)
+			continue
+		}
+		r.buf = append(data, '\n')
+	}
+	n := copy(b, r.buf)
+	r.buf = r.buf[n:]
+	return n, nil
+}
@@ -0,0 +1,114 @@
+package pkg
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// KeyCase 是 WithKeyCase 使用的键命名风格
+type KeyCase int
+
+const (
+	// KeyCaseKeep 保留原始键（默认）
+	KeyCaseKeep KeyCase = iota
+	// KeyCaseSnake 改写为 user_name
+	KeyCaseSnake
+	// KeyCaseCamel 改写为 userName
+	KeyCaseCamel
+	// KeyCasePascal 改写为 UserName
+	KeyCasePascal
+)
+
+// convertObjectKeys 按 WithKeyCase 改写对象的键；多个键改写后重名时，已经符合风格的键优先，其余键保持原样
+func (p *Parser) convertObjectKeys(obj map[string]interface{}, path string) map[string]interface{} {
+	keys := make([]string, 0, len(obj))
+	for key := range obj {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		ci, cj := convertKeyCase(keys[i], p.keyCase) == keys[i], convertKeyCase(keys[j], p.keyCase) == keys[j]
+		if ci != cj {
+			return ci
+		}
+		return keys[i] < keys[j]
+	})
+
+	out := make(map[string]interface{}, len(obj))
+	for _, key := range keys {
+		name := convertKeyCase(key, p.keyCase)
+		if _, exists := out[name]; exists && name != key {
+			p.addPathDiagnostic(KindKeyCase, SeverityWarning, childPath(path, key),
+				fmt.Sprintf("key %q was kept because %q already exists", key, name), "use one naming convention for all keys")
+			name = key
+		} else if name != key {
+			p.addPathDiagnostic(KindKeyCase, SeverityInfo, childPath(path, key),
+				fmt.Sprintf("key %q was renamed to %q", key, name), "use one naming convention for all keys")
+		}
+		out[name] = obj[key]
+	}
+	return out
+}
+
+// convertKeyCase 将 key 拆分为单词后按 style 重新拼接，无法拆出单词的键保持原样
+func convertKeyCase(key string, style KeyCase) string {
+	words := splitWords(key)
+	if len(words) == 0 {
+		return key
+	}
+	var sb strings.Builder
+	for i, word := range words {
+		switch {
+		case style == KeyCaseSnake:
+			if i > 0 {
+				sb.WriteByte('_')
+			}
+			sb.WriteString(strings.ToLower(word))
+		case style == KeyCaseCamel && i == 0:
+			sb.WriteString(strings.ToLower(word))
+		default:
+			sb.WriteString(titleWord(word))
+		}
+	}
+	return sb.String()
+}
+
+// splitWords 按分隔符与大小写边界拆分键，例如 "userID"、"user_id" 与 "HTTPServer" 分别拆为 user/ID、user/id 与 HTTP/Server
+// 数字跟随前面的单词
+func splitWords(key string) []string {
+	runes := []rune(key)
+	var words []string
+	start := -1
+	for i, c := range runes {
+		if !unicode.IsLetter(c) && !unicode.IsDigit(c) {
+			if start >= 0 {
+				words = append(words, string(runes[start:i]))
+				start = -1
+			}
+			continue
+		}
+		if start >= 0 && unicode.IsUpper(c) {
+			prev := runes[i-1]
+			acronymEnd := unicode.IsUpper(prev) && i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if unicode.IsLower(prev) || unicode.IsDigit(prev) || acronymEnd {
+				words = append(words, string(runes[start:i]))
+				start = i
+			}
+		}
+		if start < 0 {
+			start = i
+		}
+	}
+	if start >= 0 {
+		words = append(words, string(runes[start:]))
+	}
+	return words
+}
+
+// titleWord 将单词改写为首字母大写、其余小写
+func titleWord(word string) string {
+	runes := []rune(strings.ToLower(word))
+	runes[0] = unicode.ToUpper(runes[0])
+	return string(runes)
+}
@@ -0,0 +1,166 @@
+package pkg
+
+import (
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// keyValueKey 是键值对文本中允许的键
+var keyValueKey = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_.\-]*$`)
+
+// parseKeyValuePairs 尝试将剩余输入整体解析为 a=1&b=two（查询字符串）或 A=1 B=two（环境变量）形式的键值对，
+// 成功时返回对应的对象；输入中只要有一段不是键值对就返回 false，交给常规解析处理
+// 重复的键合并为数组，数字与 true、false、null 转换为对应的值
+func (p *Parser) parseKeyValuePairs() (map[string]interface{}, bool) {
+	rest := string(p.jsonStr[p.index:])
+	text := strings.TrimSpace(rest)
+	// start 是去掉前导空白后文本开头的字符偏移
+	start := p.index + utf8.RuneCountInString(rest[:len(rest)-len(strings.TrimLeftFunc(rest, unicode.IsSpace))])
+	if text == "" || strings.ContainsAny(text[:1], "{[\"'") {
+		return nil, false
+	}
+
+	var pairs []keyValuePair
+	var ok bool
+	if strings.Contains(text, "&") && !strings.ContainsFunc(text, unicode.IsSpace) {
+		pairs, ok = queryPairs(text)
+	} else {
+		pairs, ok = envPairs(text)
+	}
+	if !ok || len(pairs) == 0 {
+		return nil, false
+	}
+
+	obj := make(map[string]interface{}, len(pairs))
+	for _, pair := range pairs {
+		key, value := pair.key, p.keyValue(start+utf8.RuneCountInString(text[:pair.offset]), pair.value)
+		existing, exists := obj[key]
+		if !exists {
+			obj[key] = value
+			continue
+		}
+		// 值本身不会是数组，已有的数组只可能来自重复的键
+		if arr, ok := existing.([]interface{}); ok {
+			obj[key] = append(arr, value)
+		} else {
+			obj[key] = []interface{}{existing, value}
+		}
+	}
+	p.addDiagnostic(KindKeyValuePairs, SeverityWarning, start, len(p.jsonStr)-start,
+		"key=value text was converted into an object", "return a JSON object")
+	p.index = len(p.jsonStr)
+	return obj, true
+}
+
+// keyValuePair 是一个键值对，offset 为它在文本中开始的字节偏移
+type keyValuePair struct {
+	key, value string
+	offset     int
+}
+
+// keyValue 将键值对中的值转换为数字、布尔值或 null，其余保留为字符串；start 为该键值对开始的字符偏移
+func (p *Parser) keyValue(start int, value string) interface{} {
+	switch value {
+	case "true":
+		return true
+	case "false":
+		return false
+	case "null":
+		return nil
+	}
+	if isJSONNumber(value) {
+		return p.numberValue(start, value)
+	}
+	return value
+}
+
+// queryPairs 按 & 拆分查询字符串形式的键值对并进行 URL 解码
+func queryPairs(text string) ([]keyValuePair, bool) {
+	var pairs []keyValuePair
+	offset := 0
+	for _, part := range strings.Split(text, "&") {
+		partOffset := offset
+		offset += len(part) + 1
+		if part == "" {
+			continue
+		}
+		key, value, found := strings.Cut(part, "=")
+		if unescaped, err := url.QueryUnescape(key); err == nil {
+			key = unescaped
+		}
+		if !found || !keyValueKey.MatchString(key) {
+			return nil, false
+		}
+		if unescaped, err := url.QueryUnescape(value); err == nil {
+			value = unescaped
+		}
+		pairs = append(pairs, keyValuePair{key: key, value: value, offset: partOffset})
+	}
+	return pairs, true
+}
+
+// envPairs 按空白拆分环境变量形式的键值对，值可以用单引号或双引号包裹以包含空白
+func envPairs(text string) ([]keyValuePair, bool) {
+	var pairs []keyValuePair
+	rest := text
+	for {
+		rest = strings.TrimLeftFunc(rest, unicode.IsSpace)
+		if rest == "" {
+			return pairs, true
+		}
+		offset := len(text) - len(rest)
+		eq := strings.IndexByte(rest, '=')
+		if eq < 0 || !keyValueKey.MatchString(rest[:eq]) {
+			return nil, false
+		}
+		key := rest[:eq]
+		rest = rest[eq+1:]
+
+		var value string
+		switch {
+		case strings.HasPrefix(rest, `"`):
+			end := closingQuote(rest, '"')
+			if end < 0 {
+				return nil, false
+			}
+			unquoted, err := strconv.Unquote(rest[:end+1])
+			if err != nil {
+				return nil, false
+			}
+			value, rest = unquoted, rest[end+1:]
+		case strings.HasPrefix(rest, "'"):
+			end := strings.IndexByte(rest[1:], '\'')
+			if end < 0 {
+				return nil, false
+			}
+			value, rest = rest[1:end+1], rest[end+2:]
+		default:
+			end := strings.IndexFunc(rest, unicode.IsSpace)
+			if end < 0 {
+				end = len(rest)
+			}
+			value, rest = rest[:end], rest[end:]
+		}
+		if rest != "" && !unicode.IsSpace(rune(rest[0])) {
+			return nil, false
+		}
+		pairs = append(pairs, keyValuePair{key: key, value: value, offset: offset})
+	}
+}
+
+// closingQuote 返回 s 中与开头引号配对的结束引号的字节偏移，跳过反斜杠转义
+func closingQuote(s string, quote byte) int {
+	for i := 1; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case quote:
+			return i
+		}
+	}
+	return -1
+}
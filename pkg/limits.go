@@ -0,0 +1,52 @@
+package pkg
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// OutputLimitError 表示修复结果超过了 WithMaxElements 或 WithMaxOutputBytes 设置的上限
+type OutputLimitError struct {
+	Limit  string // "elements" 或 "bytes"
+	Max    int
+	Actual int
+}
+
+func (e *OutputLimitError) Error() string {
+	return fmt.Sprintf("repaired output has %d %s, exceeding the limit of %d", e.Actual, e.Limit, e.Max)
+}
+
+// checkOutputLimits 检查修复结果的元素数量和序列化后的字节数
+func (p *Parser) checkOutputLimits(value interface{}) error {
+	if p.maxElements > 0 {
+		if n := countElements(value); n > p.maxElements {
+			return &OutputLimitError{Limit: "elements", Max: p.maxElements, Actual: n}
+		}
+	}
+	if p.maxOutputBytes > 0 {
+		data, err := json.Marshal(value)
+		if err != nil {
+			return fmt.Errorf("failed to marshal repaired json: %w", err)
+		}
+		if len(data) > p.maxOutputBytes {
+			return &OutputLimitError{Limit: "bytes", Max: p.maxOutputBytes, Actual: len(data)}
+		}
+	}
+	return nil
+}
+
+// countElements 统计值中包含的所有节点数量（包括对象、数组及其中的标量）
+func countElements(value interface{}) int {
+	n := 1
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for _, child := range v {
+			n += countElements(child)
+		}
+	case []interface{}:
+		for _, child := range v {
+			n += countElements(child)
+		}
+	}
+	return n
+}
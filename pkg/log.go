@@ -1,11 +1,108 @@
 package pkg
 
+import (
+	"log"
+	"log/slog"
+)
+
+// RepairEvent 描述解析器执行的一次修复，随诊断信息一起产生
+type RepairEvent struct {
+	Kind     RepairKind
+	Severity Severity
+	Offset   int    // 问题在输入中的字符（rune）偏移，后处理阶段产生的事件为 -1
+	Path     string // 后处理阶段产生的事件所涉及值的路径
+	Snippet  string // 问题位置附近的输入片段，后处理阶段产生的事件与使用 WithRedactKeys 时为空
+	Message  string
+}
+
+// Logger 接收修复事件；SeverityInfo 的修复通过 Debug 报告，其余通过 Warn 报告
+// 默认使用 NopLogger，可以用 StdLogger 或 SlogLogger 适配标准库的日志
 type Logger interface {
-	Print(v ...interface{})
-	Printf(format string, v ...interface{})
-	Println(v ...interface{})
+	Debug(event RepairEvent)
+	Warn(event RepairEvent)
+}
+
+// NopLogger 返回丢弃所有事件的 Logger，是解析器的默认值
+func NopLogger() Logger {
+	return nopLogger{}
+}
+
+type nopLogger struct{}
+
+func (nopLogger) Debug(RepairEvent) {}
+func (nopLogger) Warn(RepairEvent)  {}
+
+// StdLogger 将修复事件以一行文本写入标准库的 *log.Logger
+func StdLogger(l *log.Logger) Logger {
+	return stdLogger{l}
+}
+
+type stdLogger struct {
+	l *log.Logger
+}
+
+func (s stdLogger) Debug(e RepairEvent) { s.print("debug", e) }
+func (s stdLogger) Warn(e RepairEvent)  { s.print("warn", e) }
+
+func (s stdLogger) print(level string, e RepairEvent) {
+	if e.Offset < 0 {
+		s.l.Printf("%s: %s at %s: %s", level, e.Kind, e.Path, e.Message)
+		return
+	}
+	if e.Snippet == "" {
+		s.l.Printf("%s: %s at offset %d: %s", level, e.Kind, e.Offset, e.Message)
+		return
+	}
+	s.l.Printf("%s: %s at offset %d: %s near %q", level, e.Kind, e.Offset, e.Message, e.Snippet)
+}
+
+// SlogLogger 将修复事件作为结构化记录写入 *slog.Logger，事件字段对应 kind、severity、offset、path 与 snippet 属性
+func SlogLogger(l *slog.Logger) Logger {
+	return slogLogger{l}
+}
+
+type slogLogger struct {
+	l *slog.Logger
+}
+
+func (s slogLogger) Debug(e RepairEvent) { s.l.Debug(e.Message, s.attrs(e)...) }
+func (s slogLogger) Warn(e RepairEvent)  { s.l.Warn(e.Message, s.attrs(e)...) }
+
+func (s slogLogger) attrs(e RepairEvent) []any {
+	attrs := []any{slog.String("kind", string(e.Kind)), slog.String("severity", e.Severity.String())}
+	if e.Offset < 0 {
+		return append(attrs, slog.String("path", e.Path))
+	}
+	attrs = append(attrs, slog.Int("offset", e.Offset))
+	if e.Snippet != "" {
+		attrs = append(attrs, slog.String("snippet", e.Snippet))
+	}
+	return attrs
+}
+
+// snippetContext 是事件片段在问题位置前后各保留的字符数
+const snippetContext = 16
 
-	Fatal(v ...interface{})
-	Fatalf(format string, v ...interface{})
-	Fatalln(v ...interface{})
+// logRepair 将一条诊断信息作为修复事件报告给 Logger
+func (p *Parser) logRepair(d Diagnostic) {
+	event := RepairEvent{
+		Kind:     d.Kind,
+		Severity: d.Severity,
+		Offset:   d.Offset,
+		Path:     d.Path,
+		Message:  d.Message,
+	}
+	// 使用 WithRedactKeys 时片段可能包含被脱敏的值，不输出片段
+	if d.Offset >= 0 && len(p.redactKeys) == 0 {
+		start := max(0, d.Offset-snippetContext)
+		end := min(len(p.jsonStr), d.Offset+min(d.Length, snippetContext)+snippetContext)
+		if start < end {
+			event.Snippet = string(p.jsonStr[start:end])
+		}
+	}
+	if d.Severity == SeverityInfo {
+		p.logger.Debug(event)
+	} else {
+		p.logger.Warn(event)
+	}
 }
@@ -0,0 +1,100 @@
+package pkg
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"iter"
+	"strings"
+)
+
+// LogRecord 是日志中一行内嵌的 JSON 修复后的结果
+type LogRecord struct {
+	Line   int    // 从 1 开始的行号
+	Prefix string // JSON 之前的内容，例如时间戳与日志级别，已去掉首尾空白
+	Value  interface{}
+}
+
+// maxLogLine 是 LogValues 支持的最长日志行
+const maxLogLine = 16 << 20
+
+// LogValues 逐行读取日志流，找出每行中时间戳、日志级别等前缀之后内嵌的对象或数组，修复后连同行号惰性地产出
+// 不含 JSON 的行会被跳过；某一行修复失败时产出该行的 LogRecord 与错误，调用方可以选择继续；读取失败时产出错误后结束
+func LogValues(r io.Reader, opts ...Option) iter.Seq2[LogRecord, error] {
+	return func(yield func(LogRecord, error) bool) {
+		r, opts := decodeStream(r, opts)
+		scanner := bufio.NewScanner(r)
+		scanner.Buffer(make([]byte, 0, 64*1024), maxLogLine)
+		for line := 1; scanner.Scan(); line++ {
+			text := scanner.Text()
+			start, end, ok := embeddedJSON(text)
+			if !ok {
+				continue
+			}
+			record := LogRecord{Line: line, Prefix: strings.TrimSpace(text[:start])}
+			value, err := Loads(text[start:end], opts...)
+			if err != nil {
+				err = fmt.Errorf("line %d: %w", line, err)
+			}
+			record.Value = value
+			if !yield(record, err) {
+				return
+			}
+		}
+		if err := scanner.Err(); err != nil {
+			yield(LogRecord{}, fmt.Errorf("failed to read input: %w", err))
+		}
+	}
+}
+
+// embeddedJSON 返回行内第一个 JSON 对象或数组的字节范围，未闭合时延伸到行尾
+// 形如 [INFO]、[2024-01-01 12:00:00] 的方括号前缀不视为数组
+func embeddedJSON(line string) (int, int, bool) {
+	for start := 0; start < len(line); start++ {
+		if line[start] != '{' && line[start] != '[' {
+			continue
+		}
+		end, closed := containerEnd(line, start)
+		if line[start] == '[' && !arrayStart(line[start+1:]) && !(closed && json.Valid([]byte(line[start:end]))) {
+			continue
+		}
+		return start, end, true
+	}
+	return 0, 0, false
+}
+
+// arrayStart 判断方括号之后的内容是否像数组元素的开始：对象、数组或字符串
+func arrayStart(rest string) bool {
+	rest = strings.TrimLeft(rest, " \t")
+	return rest != "" && strings.ContainsRune(`{["'`, rune(rest[0]))
+}
+
+// containerEnd 返回从 start 开始的容器结束之后的偏移，容器未闭合时返回行尾
+func containerEnd(line string, start int) (int, bool) {
+	depth := 0
+	var quote byte
+	for i := start; i < len(line); i++ {
+		c := line[i]
+		if quote != 0 {
+			if c == '\\' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+			continue
+		}
+		switch c {
+		case '"', '\'':
+			quote = c
+		case '{', '[':
+			depth++
+		case '}', ']':
+			depth--
+			if depth == 0 {
+				return i + 1, true
+			}
+		}
+	}
+	return len(line), false
+}
@@ -0,0 +1,45 @@
+package pkg
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// RepairLoop 调用 call 获取模型输出，修复并解码为 T 后交给 validate 校验，
+// 调用、解码或校验失败时重新调用，最多尝试 maxAttempts 次（小于 1 时按 1 次处理）
+// validate 可以为 nil；全部失败时返回的错误合并了每次尝试的错误，ctx 结束时立即返回
+func RepairLoop[T any](ctx context.Context, call func(ctx context.Context) (string, error), validate func(T) error, maxAttempts int, opts ...Option) (T, error) {
+	var zero T
+	attempts := max(maxAttempts, 1)
+	var errs []error
+	for attempt := 1; attempt <= attempts; attempt++ {
+		if err := ctx.Err(); err != nil {
+			return zero, errors.Join(append(errs, err)...)
+		}
+		v, err := repairAttempt(ctx, call, validate, opts)
+		if err == nil {
+			return v, nil
+		}
+		errs = append(errs, fmt.Errorf("attempt %d: %w", attempt, err))
+	}
+	return zero, errors.Join(errs...)
+}
+
+// repairAttempt 执行 RepairLoop 中的一次尝试
+func repairAttempt[T any](ctx context.Context, call func(ctx context.Context) (string, error), validate func(T) error, opts []Option) (T, error) {
+	var v T
+	output, err := call(ctx)
+	if err != nil {
+		return v, fmt.Errorf("call failed: %w", err)
+	}
+	if err := Unmarshal(output, &v, opts...); err != nil {
+		return v, err
+	}
+	if validate != nil {
+		if err := validate(v); err != nil {
+			return v, fmt.Errorf("validation failed: %w", err)
+		}
+	}
+	return v, nil
+}
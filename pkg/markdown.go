@@ -0,0 +1,186 @@
+package pkg
+
+import (
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// codeSpan 在以说明文字开头的输入中查找第一个内容为对象或数组的 Markdown 代码片段，
+// 支持 ```json 围栏代码块（可以未闭合）和单个反引号包裹的行内代码，返回内容的起止偏移
+func (p *Parser) codeSpan() (int, int, bool) {
+	s := p.jsonStr
+	i := 0
+	for i < len(s) && unicode.IsSpace(s[i]) {
+		i++
+	}
+	if i == len(s) || s[i] == '{' || s[i] == '[' {
+		return 0, 0, false
+	}
+
+	for ; i < len(s); i++ {
+		if s[i] != '`' {
+			continue
+		}
+		if isFence(s, i) {
+			start := i + 3
+			// 跳过语言标记
+			for start < len(s) && s[start] != '\n' {
+				start++
+			}
+			end := start
+			for end < len(s) && !isFence(s, end) {
+				end++
+			}
+			if startsContainer(s[start:end]) {
+				return start, end, true
+			}
+			i = end + 2
+			continue
+		}
+		end := i + 1
+		for end < len(s) && s[end] != '`' && s[end] != '\n' {
+			end++
+		}
+		if end < len(s) && s[end] == '`' && startsContainer(s[i+1:end]) {
+			return i + 1, end, true
+		}
+		i = end - 1
+	}
+	return 0, 0, false
+}
+
+// isFence 判断 s[i:] 是否以 ``` 开头
+func isFence(s []rune, i int) bool {
+	return i+3 <= len(s) && s[i] == '`' && s[i+1] == '`' && s[i+2] == '`'
+}
+
+// startsContainer 判断去掉前导空白后的内容是否以 { 或 [ 开头
+func startsContainer(s []rune) bool {
+	for _, c := range s {
+		if !unicode.IsSpace(c) {
+			return c == '{' || c == '['
+		}
+	}
+	return false
+}
+
+// tagSpan 在以说明文字开头的输入中查找第一个内容为对象或数组的 XML 标签（例如 <json>…</json>），
+// 返回标签内容的起止偏移；缺少结束标签时内容延伸到输入末尾，内容不是 JSON 的标签（例如 <thinking>）整体跳过
+func (p *Parser) tagSpan() (int, int, bool) {
+	s := p.jsonStr
+	i := 0
+	for i < len(s) && unicode.IsSpace(s[i]) {
+		i++
+	}
+	if i == len(s) || s[i] == '{' || s[i] == '[' {
+		return 0, 0, false
+	}
+
+	for ; i < len(s); i++ {
+		name, start, ok := openingTag(s, i)
+		if !ok {
+			continue
+		}
+		end := start
+		closing := "</" + name + ">"
+		for end < len(s) && !p.hasPrefixAt(end, closing) {
+			end++
+		}
+		if startsContainer(s[start:end]) {
+			p.addDiagnostic(KindXMLTag, SeverityInfo, i, start-i,
+				"extracted the content of the <"+name+"> tag", "return only the JSON")
+			return start, end, true
+		}
+		i = end + utf8.RuneCountInString(closing) - 1
+	}
+	return 0, 0, false
+}
+
+// openingTag 判断 s[i:] 是否为开始标签 <name ...>，返回标签名与标签之后的偏移
+func openingTag(s []rune, i int) (string, int, bool) {
+	if s[i] != '<' {
+		return "", 0, false
+	}
+	j := i + 1
+	for j < len(s) && (unicode.IsLetter(s[j]) || unicode.IsDigit(s[j]) || s[j] == '_' || s[j] == '-' || s[j] == ':') {
+		j++
+	}
+	if j == i+1 || !unicode.IsLetter(s[i+1]) {
+		return "", 0, false
+	}
+	name := string(s[i+1 : j])
+	for j < len(s) && s[j] != '>' && s[j] != '<' && s[j] != '\n' {
+		j++
+	}
+	if j == len(s) || s[j] != '>' || s[j-1] == '/' {
+		return "", 0, false
+	}
+	return name, j + 1, true
+}
+
+// extractCode 将解析范围限制在输入中的代码片段内，片段外的说明文字记录为跳过的内容
+// 返回的函数恢复完整输入，应在解析结束后调用
+func (p *Parser) extractCode() func() {
+	full := p.jsonStr
+	if p.xmlTags {
+		p.blankReasoning()
+	}
+	start, end, ok := p.codeSpan()
+	if !ok && p.xmlTags {
+		start, end, ok = p.tagSpan()
+	}
+	if !ok {
+		return func() { p.jsonStr = full }
+	}
+	p.addDiagnostic(KindSkippedGarbage, SeverityInfo, 0, start,
+		"skipped text before the code snippet", "return only the JSON")
+	if end < len(p.jsonStr) {
+		p.addDiagnostic(KindSkippedGarbage, SeverityInfo, end, len(p.jsonStr)-end,
+			"skipped text after the code snippet", "return only the JSON")
+	}
+	p.jsonStr = p.jsonStr[:end]
+	p.index = start
+	return func() { p.jsonStr = full }
+}
+
+// reasoningTags 是模型输出推理过程时使用的标签，其中的内容不是答案
+var reasoningTags = []string{"thinking", "think", "reasoning"}
+
+// blankReasoning 将已闭合的推理标签（例如 <thinking>…</thinking>）连同内容替换为空格，
+// 使其中的代码片段和 JSON 不会被当作答案提取；只处理第一个 { 或 [ 之前的推理块，
+// 替换保持偏移不变，未闭合的标签保持原样
+func (p *Parser) blankReasoning() {
+	var blanked []rune
+	for i := 0; i < len(p.jsonStr); i++ {
+		if p.jsonStr[i] == '{' || p.jsonStr[i] == '[' {
+			break
+		}
+		name, start, ok := openingTag(p.jsonStr, i)
+		if !ok || !slices.Contains(reasoningTags, strings.ToLower(name)) {
+			continue
+		}
+		closing := "</" + name + ">"
+		end := start
+		for end < len(p.jsonStr) && !p.hasPrefixAt(end, closing) {
+			end++
+		}
+		if end == len(p.jsonStr) {
+			break
+		}
+		end += utf8.RuneCountInString(closing)
+		if blanked == nil {
+			blanked = slices.Clone(p.jsonStr)
+			p.jsonStr = blanked
+		}
+		p.addDiagnostic(KindSkippedGarbage, SeverityInfo, i, end-i,
+			"skipped the <"+name+"> block", "return only the JSON")
+		for j := i; j < end; j++ {
+			if blanked[j] != '\n' {
+				blanked[j] = ' '
+			}
+		}
+		i = end - 1
+	}
+}
@@ -0,0 +1,39 @@
+package pkg
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// RepairToNDJSON 修复顶层为数组（可以是被截断的）的输入，并将每个元素以紧凑 JSON 的形式逐行写入 w；
+// 整个输入作为一个数组修复，路径、骨架等选项与 Repair 一样相对于顶层数组生效；
+// 输入以对象开头时（例如多个连续的对象）逐个写出合并后的各个对象，修复结果不是数组时写出一行
+func RepairToNDJSON(jsonStr string, w io.Writer, opts ...Option) error {
+	value, err := Loads(jsonStr, opts...)
+	if err != nil {
+		return err
+	}
+	arr, ok := value.([]interface{})
+	if !ok {
+		arr = []interface{}{value}
+	}
+	for i, elem := range arr {
+		if err := writeNDJSONLine(w, elem); err != nil {
+			return fmt.Errorf("array element %d: %w", i, err)
+		}
+	}
+	return nil
+}
+
+// writeNDJSONLine 将 value 编码为一行紧凑 JSON 写入 w
+func writeNDJSONLine(w io.Writer, value interface{}) error {
+	line, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("failed to marshal repaired json: %w", err)
+	}
+	if _, err := w.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("failed to write ndjson: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,37 @@
+package pkg
+
+import (
+	"fmt"
+	"sort"
+)
+
+// normalizeObjectKeys 按 WithUnicodeNormalization 规范化对象的键
+// 规范化后相同的键只保留一个：原本就是规范形式的键优先，其余按字典序取第一个
+func (p *Parser) normalizeObjectKeys(obj map[string]interface{}, path string) map[string]interface{} {
+	keys := make([]string, 0, len(obj))
+	for key := range obj {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		ni, nj := p.normForm.IsNormalString(keys[i]), p.normForm.IsNormalString(keys[j])
+		if ni != nj {
+			return ni
+		}
+		return keys[i] < keys[j]
+	})
+
+	out := make(map[string]interface{}, len(obj))
+	kept := make(map[string]string, len(obj))
+	for _, key := range keys {
+		name := p.normForm.String(key)
+		if first, exists := kept[name]; exists {
+			p.addPathDiagnostic(KindNormalizedKey, SeverityWarning, childPath(path, key),
+				fmt.Sprintf("key %q is identical to %q after normalization and was dropped", key, first),
+				"emit each key only once")
+			continue
+		}
+		kept[name] = key
+		out[name] = obj[key]
+	}
+	return out
+}
@@ -1,10 +1,15 @@
 package pkg
 
 import (
-	"log"
+	"errors"
+	"fmt"
 	"strconv"
 	"strings"
 	"unicode"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/htmlindex"
+	"golang.org/x/text/unicode/norm"
 )
 
 const (
@@ -13,39 +18,464 @@ const (
 	inObjectValue
 )
 
-type Option func(p *parser)
+type Option func(p *Parser)
+
+// Parser 是核心的 JSON 解析和修复结构体
+type Parser struct {
+	raw      string
+	rawBytes []byte // newBytesParser 保留的调用方输入，尚未转换为 raw 与 jsonStr
+	jsonStr  []rune
+	index    int
+	context  *jsonContext
+	logger   Logger
+
+	diagnostics []Diagnostic
+	fallback    bool // 输入不是合法 JSON，需要启动修复程序
+	explanation *string
+	stats       *ParseStats
+
+	maxStringLength  int
+	charset          encoding.Encoding
+	invalidUTF8      InvalidUTF8Policy
+	writeAlongside   bool
+	streamingInput   bool
+	sanitizeKeys     bool
+	keyCase          KeyCase
+	keyMapping       *keyRecorder
+	unflatten        bool
+	rules            []RepairRule
+	skeleton         interface{}
+	transforms       []valueTransform
+	redactKeys       map[string]struct{}
+	maxElements      int
+	maxOutputBytes   int
+	maxArrayLength   int
+	strictCheck      bool
+	fullWidth        bool
+	xmlTags          bool
+	flagKeys         bool
+	noSetLiterals    bool
+	keyValuePairs    bool
+	unparseable      UnparseablePolicy
+	comments         bool
+	commentsOut      *[]Comment
+	capturedComments []Comment
+	attachedComments int    // capturedComments 中已关联键的注释数
+	memberKey        string // 最近一个成员的键
+	memberEnd        int    // 最近一个成员结束的位置，成员未结束时为 -1
+	cache            *repairCache
+	decimalComma     bool
+	units            UnitPolicy
+	truncatedNumbers TruncatedNumberPolicy
+	scalarPolicy     TopLevelScalarPolicy
+	templates        bool
+	normalize        bool
+	normForm         norm.Form
+	progress         func(processed, total int)
+	garbageStrategy  GarbageStrategy
+	maxGarbage       int
+	includeKeys      []pathPattern
+	excludeKeys      []pathPattern
+	pendingUnit      string      // UnitsSplit 下刚解析的数字的单位，由 parseObject 写入同级成员
+	filterTokens     []pathToken // 正在解析的值的路径，用于在解析时按 WithIncludeKeys 与 WithExcludeKeys 筛选
+	filterIncluded   bool        // 当前路径已被包含规则完整匹配
+	filtered         bool        // 本次解析已经在解析时完成筛选
+
+	expected    interface{}            // 骨架中与当前正在解析的值对应的部分
+	objectShape map[string]interface{} // 骨架中与当前所在对象对应的部分
 
-// parser 是核心的 JSON 解析和修复结构体
-type parser struct {
-	jsonStr []rune
-	index   int
-	context *jsonContext
-	logger  Logger
+	progressTotal int   // 本次解析的输入长度（字符数）
+	lastProgress  int   // 上次报告进度时的索引
+	skipped       int   // 本次解析累计跳过的字符数
+	garbageErr    error // 跳过的内容超过上限时的错误
+	valueErr      error // 使用 UnparseableError 时第一个无法解析的成员值
+
+	optionErr error // 选项配置错误，在解析时返回
+	inputErr  error // 输入预处理错误，每次 Reset 时重新计算
+	decoding  bool  // 正在解码输入，此时产生的诊断信息延后报告给 Logger
 }
 
 // NewParser 创建一个新的解析器实例
-func NewParser(jsonStr string, opts ...Option) *parser {
-	p := &parser{
-		logger:  &log.Logger{},
-		jsonStr: []rune(jsonStr),
-		index:   0,
+func NewParser(jsonStr string, opts ...Option) *Parser {
+	p := &Parser{
+		logger:  NopLogger(),
 		context: &jsonContext{},
 	}
 	for _, opt := range opts {
 		opt(p)
 	}
+	p.Reset(jsonStr)
 	return p
 }
 
-// WithLogger 设置日志
+// Reset 使用新的输入重置解析器，保留已配置的选项并复用内部缓冲区
+func (p *Parser) Reset(jsonStr string) {
+	p.index = 0
+	p.context.stack = p.context.stack[:0]
+	p.diagnostics = p.diagnostics[:0]
+	p.fallback = false
+	p.inputErr = nil
+	p.pendingUnit = ""
+	p.filtered = false
+	p.rawBytes = nil
+	p.skipped, p.garbageErr, p.valueErr = 0, nil, nil
+	p.capturedComments, p.attachedComments, p.memberEnd = p.capturedComments[:0], 0, -1
+
+	// 解码阶段的诊断信息在输入缓冲区填充之后再报告给 Logger，保证事件片段来自本次输入
+	p.decoding = true
+	p.raw = p.decodeInput(jsonStr)
+	p.decoding = false
+	p.jsonStr = p.jsonStr[:0]
+	for _, r := range p.raw {
+		p.jsonStr = append(p.jsonStr, r)
+	}
+	for _, d := range p.diagnostics {
+		p.logRepair(d)
+	}
+}
+
+// WithLogger 设置接收修复事件的日志记录器，默认为 NopLogger
 func WithLogger(l Logger) Option {
-	return func(p *parser) {
+	return func(p *Parser) {
+		if l == nil {
+			l = NopLogger()
+		}
 		p.logger = l
 	}
 }
 
+// WithExplanation 在解析结束后将修复过程的文字说明写入 out
+func WithExplanation(out *string) Option {
+	return func(p *Parser) {
+		p.explanation = out
+	}
+}
+
+// WithStats 在解析结束后将覆盖率统计写入 out
+func WithStats(out *ParseStats) Option {
+	return func(p *Parser) {
+		p.stats = out
+	}
+}
+
+// WithMaxStringLength 将超过 n 个字符的字符串值截断，并在末尾追加省略号
+func WithMaxStringLength(n int) Option {
+	return func(p *Parser) {
+		p.maxStringLength = n
+	}
+}
+
+// WithCharset 指定输入的字符集（例如 "gbk"、"gb18030"、"latin1"），在修复前将其转换为 UTF-8
+func WithCharset(name string) Option {
+	return func(p *Parser) {
+		enc, err := htmlindex.Get(name)
+		if err != nil {
+			p.optionErr = fmt.Errorf("unsupported charset %q: %w", name, err)
+			return
+		}
+		p.charset = enc
+	}
+}
+
+// WithInvalidUTF8 设置输入中出现非法 UTF-8 字节序列时的处理策略
+func WithInvalidUTF8(policy InvalidUTF8Policy) Option {
+	return func(p *Parser) {
+		p.invalidUTF8 = policy
+	}
+}
+
+// WithWriteAlongside 使 RepairFile 将结果写入同目录下的 .repaired.json 文件，而不是覆盖原文件
+func WithWriteAlongside() Option {
+	return func(p *Parser) {
+		p.writeAlongside = true
+	}
+}
+
+// WithStreamingInput 使 RepairFile 分块读取文件并逐个记号写出结果（见 RepairStream），
+// 用于修复无法整体读入内存的大文件；此模式下结果为紧凑格式，只能与 WithWriteAlongside 同时使用，
+// 与其他选项同时使用时 RepairFile 返回错误。该选项只对 RepairFile 生效，Repair、Loads 等函数会忽略它
+func WithStreamingInput() Option {
+	return func(p *Parser) {
+		p.streamingInput = true
+	}
+}
+
+// WithSanitizedKeys 将输出中的键改写为合法标识符（ASCII 字母、数字和下划线，且不以数字开头），
+// 适用于 BigQuery、Avro 等对字段名有限制的目标。mapping 不为 nil 时会记录原始键到新键的映射；
+// 在 RepairBatch、RepairFS 中多个协程共用同一个 mapping 时写入是加锁的，应在修复全部结束后再读取它
+func WithSanitizedKeys(mapping map[string]string) Option {
+	var recorder *keyRecorder
+	if mapping != nil {
+		recorder = &keyRecorder{mapping: mapping}
+	}
+	return func(p *Parser) {
+		p.sanitizeKeys = true
+		p.keyMapping = recorder
+	}
+}
+
+// WithKeyCase 将输出中所有对象的键改写为统一的命名风格，例如 KeyCaseSnake 将 userName 与 UserName 都改写为 user_name
+func WithKeyCase(style KeyCase) Option {
+	return func(p *Parser) {
+		p.keyCase = style
+	}
+}
+
+// WithUnflatten 将 "user.name"、"user.orgs[0]" 这类点号路径键还原为嵌套的对象和数组
+func WithUnflatten() Option {
+	return func(p *Parser) {
+		p.unflatten = true
+	}
+}
+
+// WithRepairRule 注册一个自定义修复规则，多个规则按注册顺序尝试
+func WithRepairRule(rule RepairRule) Option {
+	return func(p *Parser) {
+		p.rules = append(p.rules, rule)
+	}
+}
+
+// WithSkeleton 提供期望的输出骨架，例如 {"items": [{"name": "", "qty": 0}]}
+// 解析时骨架中的键用于区分未加引号的键和值，解析后按骨架中的类型转换字段，
+// 并在骨架为数组而输出不是数组时将值包装为数组
+func WithSkeleton(skeleton string) Option {
+	return func(p *Parser) {
+		shape, err := Loads(skeleton)
+		if err != nil {
+			p.optionErr = fmt.Errorf("invalid skeleton: %w", err)
+			return
+		}
+		p.skeleton = shape
+	}
+}
+
+// WithTransform 在修复过程中对路径匹配 path 的值调用 fn，并使用其返回值替换原值
+// path 支持 $.a.b、$.items[0]、$["a b"] 以及通配符 $.items[*].price
+func WithTransform(path string, fn func(v interface{}) interface{}) Option {
+	return func(p *Parser) {
+		pattern, err := compilePathPattern(path)
+		if err != nil {
+			p.optionErr = err
+			return
+		}
+		p.transforms = append(p.transforms, valueTransform{pattern: pattern, fn: fn})
+	}
+}
+
+// WithUnicodeNormalization 将键和字符串值规范化为指定的 Unicode 形式（通常为 norm.NFC），
+// 使组合字符与分解字符写法不同但看起来相同的键合并为一个
+func WithUnicodeNormalization(form norm.Form) Option {
+	return func(p *Parser) {
+		p.normalize = true
+		p.normForm = form
+	}
+}
+
+// WithRedactKeys 将指定键（忽略大小写）的值替换为 "[REDACTED]"，便于安全地记录修复结果
+// 输入中的原始键或经 WithSanitizedKeys、WithKeyCase 等改写后的键与之相同时都会屏蔽
+func WithRedactKeys(keys ...string) Option {
+	return func(p *Parser) {
+		if p.redactKeys == nil {
+			p.redactKeys = make(map[string]struct{}, len(keys))
+		}
+		for _, key := range keys {
+			p.redactKeys[strings.ToLower(key)] = struct{}{}
+		}
+	}
+}
+
+// WithIncludeKeys 只保留与路径表达式（例如 $.user.name、$.items[*].id）匹配的成员及其子值，
+// 以及通向它们的对象和数组；可以多次使用，匹配任意一条即保留
+// 需要修复的输入在解析时直接跳过其余部分，不为它们构建值，其中的问题也不会记录为诊断信息；
+// 本身合法的输入先由标准库完整解码，再删除不需要的部分
+func WithIncludeKeys(patterns ...string) Option {
+	return func(p *Parser) {
+		for _, path := range patterns {
+			pattern, err := compilePathPattern(path)
+			if err != nil {
+				p.optionErr = err
+				return
+			}
+			p.includeKeys = append(p.includeKeys, pattern)
+		}
+	}
+}
+
+// WithExcludeKeys 删除与路径表达式匹配的成员及其子值，优先于 WithIncludeKeys
+func WithExcludeKeys(patterns ...string) Option {
+	return func(p *Parser) {
+		for _, path := range patterns {
+			pattern, err := compilePathPattern(path)
+			if err != nil {
+				p.optionErr = err
+				return
+			}
+			p.excludeKeys = append(p.excludeKeys, pattern)
+		}
+	}
+}
+
+// WithMaxElements 限制修复结果中的节点总数，超出时返回 *OutputLimitError
+func WithMaxElements(n int) Option {
+	return func(p *Parser) {
+		p.maxElements = n
+	}
+}
+
+// WithMaxArrayElements 每个数组最多保留 n 个元素，之后的元素在解析时丢弃，
+// 丢弃的数量记录在 truncated_array 诊断中
+func WithMaxArrayElements(n int) Option {
+	return func(p *Parser) {
+		p.maxArrayLength = n
+	}
+}
+
+// WithStrictCheck 在 Repair、RepairBytes 与 RepairFile（非流式）输出前按 RFC 8259 严格检查修复结果，
+// 不合规时返回 *StrictCheckError 而不是输出结果
+func WithStrictCheck() Option {
+	return func(p *Parser) {
+		p.strictCheck = true
+	}
+}
+
+// WithMaxOutputBytes 限制修复结果紧凑序列化后的字节数，超出时返回 *OutputLimitError
+func WithMaxOutputBytes(n int) Option {
+	return func(p *Parser) {
+		p.maxOutputBytes = n
+	}
+}
+
+// WithDecimalComma 将 3,14 与 1.234,56 这样使用逗号作为小数点的数字解析为小数
+// 仅在逗号后紧跟数字时生效，因此启用后没有空格的 [1,2] 在需要修复时会被解析为 [1.2]
+func WithDecimalComma() Option {
+	return func(p *Parser) {
+		p.decimalComma = true
+	}
+}
+
+// WithTruncatedNumbers 设置输入在数字中间结束时的补全方式
+func WithTruncatedNumbers(policy TruncatedNumberPolicy) Option {
+	return func(p *Parser) {
+		p.truncatedNumbers = policy
+	}
+}
+
+// WithTopLevelScalar 设置修复结果的顶层为标量时的处理方式：ScalarAllow、ScalarError 或 WrapInObject(key)，
+// WrapInObject("") 没有可用的键，解析时返回错误
+func WithTopLevelScalar(policy TopLevelScalarPolicy) Option {
+	return func(p *Parser) {
+		if policy.wrap && policy.key == "" {
+			p.optionErr = errors.New("WrapInObject requires a non-empty key")
+		}
+		p.scalarPolicy = policy
+	}
+}
+
+// WithUnits 设置 3kg、250ms 这类带单位后缀的数字的处理方式
+func WithUnits(policy UnitPolicy) Option {
+	return func(p *Parser) {
+		p.units = policy
+	}
+}
+
+// WithTemplatePlaceholders 将未加引号的模板占位符（{{var}}、${var}）作为字符串值原样保留，
+// 使修复后的提示词模板仍然可用
+func WithTemplatePlaceholders() Option {
+	return func(p *Parser) {
+		p.templates = true
+	}
+}
+
+// WithFullWidthPunctuation 将字符串之外的全角括号、冒号、逗号和中文引号视为对应的 ASCII 字符，
+// 常见于中文模型的输出，例如 ｛“name”：“张三”｝
+func WithFullWidthPunctuation() Option {
+	return func(p *Parser) {
+		p.fullWidth = true
+	}
+}
+
+// WithXMLTags 在输入以说明文字开头时提取第一个内容为 JSON 的 XML 标签（例如 <json>…</json>），
+// 内容不是 JSON 的标签会被跳过；<thinking>、<think>、<reasoning> 推理块中的内容即使是 JSON 也不会被提取
+func WithXMLTags() Option {
+	return func(p *Parser) {
+		p.xmlTags = true
+	}
+}
+
+// WithFlagKeys 将对象中没有冒号和值的键视为取值 true 的开关，
+// 例如 {"verbose", "dry_run", "name": "x"} 修复为 {"verbose": true, "dry_run": true, "name": "x"}
+func WithFlagKeys() Option {
+	return func(p *Parser) {
+		p.flagKeys = true
+	}
+}
+
+// WithKeyValuePairs 在输入整体为 a=1&b=two 或 A=1 B=two 形式的键值对文本时将其转换为对象，
+// 常见于要求模型返回参数时得到的回答
+func WithKeyValuePairs() Option {
+	return func(p *Parser) {
+		p.keyValuePairs = true
+	}
+}
+
+// WithComments 将 // 行注释与 /* */ 块注释视为空白跳过
+func WithComments() Option {
+	return func(p *Parser) {
+		p.comments = true
+	}
+}
+
+// WithCommentCapture 启用注释支持，并在解析结束后将注释连同位置和其后的对象键写入 out，
+// 模型常在字段旁的注释中给出重要的说明
+func WithCommentCapture(out *[]Comment) Option {
+	return func(p *Parser) {
+		p.comments = true
+		p.commentsOut = out
+	}
+}
+
+// WithUnparseableValue 设置对象成员缺少值或值无法解析时的处理方式，默认存为空字符串
+func WithUnparseableValue(policy UnparseablePolicy) Option {
+	return func(p *Parser) {
+		p.unparseable = policy
+	}
+}
+
+// WithProgress 在解析过程中定期调用 fn 报告进度，processed 与 total 以字符（rune）计，
+// 解析结束时总会以 processed == total 调用一次
+func WithProgress(fn func(processed, total int)) Option {
+	return func(p *Parser) {
+		p.progress = fn
+	}
+}
+
+// progressInterval 是两次进度报告之间至少解析的字符数
+const progressInterval = 1 << 16
+
+// reportProgress 在距离上次报告超过 progressInterval 个字符时报告进度
+func (p *Parser) reportProgress() {
+	if p.progress != nil && p.index-p.lastProgress >= progressInterval {
+		p.lastProgress = p.index
+		p.progress(p.index, p.progressTotal)
+	}
+}
+
+// WithGarbageStrategy 设置遇到无法识别的内容时的跳过方式
+func WithGarbageStrategy(strategy GarbageStrategy) Option {
+	return func(p *Parser) {
+		p.garbageStrategy = strategy
+	}
+}
+
+// WithMaxGarbage 限制解析时累计跳过的字符数，超出时停止解析并返回 ErrTooMuchGarbage
+func WithMaxGarbage(n int) Option {
+	return func(p *Parser) {
+		p.maxGarbage = n
+	}
+}
+
 // getChar 安全地获取当前索引或偏移处的字符
-func (p *parser) getChar(offset int) (rune, bool) {
+func (p *Parser) getChar(offset int) (rune, bool) {
 	if p.index+offset >= len(p.jsonStr) || p.index+offset < 0 {
 		return 0, false
 	}
@@ -53,9 +483,13 @@ func (p *parser) getChar(offset int) (rune, bool) {
 }
 
 // skipWhitespace 跳过所有空白字符
-func (p *parser) skipWhitespace() {
+func (p *Parser) skipWhitespace() {
 	for {
 		char, ok := p.getChar(0)
+		if ok && p.atComment() {
+			p.skipComment()
+			continue
+		}
 		if !ok || !unicode.IsSpace(char) {
 			break
 		}
@@ -64,7 +498,73 @@ func (p *parser) skipWhitespace() {
 }
 
 // Parse 解析器的启动方法
-func (p *parser) Parse() (interface{}, error) {
+func (p *Parser) Parse() (interface{}, error) {
+	if err := p.setupErr(); err != nil {
+		return nil, err
+	}
+	json, err := p.parse()
+	if err == nil {
+		json, err = p.postProcess(json)
+	}
+	p.finish()
+	return json, err
+}
+
+// setupErr 返回选项配置或输入预处理阶段产生的错误
+func (p *Parser) setupErr() error {
+	if p.optionErr != nil {
+		return p.optionErr
+	}
+	return p.inputErr
+}
+
+// finish 在解析结束后输出附加信息
+func (p *Parser) finish() {
+	if p.progress != nil || p.stats != nil {
+		p.materialize()
+	}
+	if p.progress != nil {
+		p.progress(len(p.jsonStr), len(p.jsonStr))
+	}
+	if p.explanation != nil {
+		*p.explanation = explain(p.Diagnostics())
+	}
+	if p.stats != nil {
+		*p.stats = p.Stats()
+	}
+	if p.commentsOut != nil {
+		*p.commentsOut = append([]Comment(nil), p.capturedComments...)
+	}
+}
+
+// parse 解析顶层的一个或多个 JSON 值
+func (p *Parser) parse() (interface{}, error) {
+	p.expected, p.objectShape = p.skeleton, nil
+	p.filterTokens, p.filterIncluded = p.filterTokens[:0], false
+	p.filtered = len(p.includeKeys) > 0 || len(p.excludeKeys) > 0
+	p.progressTotal, p.lastProgress = len(p.jsonStr), 0
+	defer p.replaceFullWidth()()
+	defer p.extractCode()()
+	if p.keyValuePairs {
+		p.skipWhitespace()
+		if obj, ok := p.parseKeyValuePairs(); ok {
+			// key=value 输入不经过 parseObject，交给 postProcess 筛选
+			p.filtered = false
+			return obj, nil
+		}
+	}
+	value, err := p.parseDocuments()
+	if p.garbageErr != nil {
+		return nil, p.garbageErr
+	}
+	if p.valueErr != nil {
+		return nil, p.valueErr
+	}
+	return value, err
+}
+
+// parseDocuments 解析一个顶层值，其后还有内容时将所有顶层值合并为数组
+func (p *Parser) parseDocuments() (interface{}, error) {
 	json, err := p.parseJSON()
 	if err != nil {
 		return nil, err
@@ -79,11 +579,17 @@ func (p *parser) Parse() (interface{}, error) {
 			if p.index == len(p.jsonStr) {
 				break
 			}
+			start := p.index
 			nextJSON, err := p.parseJSON()
 			if err == nil && nextJSON != nil {
+				if len(results) == 1 {
+					p.addDiagnostic(KindMultipleDocuments, SeverityWarning, start, 0,
+						"multiple top-level values were found", "wrap the values in an array")
+				}
 				results = append(results, nextJSON)
-			} else {
-				p.index++
+				p.expected = p.skeleton
+			} else if p.index < len(p.jsonStr) {
+				p.skipGarbage()
 			}
 		}
 		if len(results) == 1 {
@@ -96,22 +602,41 @@ func (p *parser) Parse() (interface{}, error) {
 }
 
 // parseJSON 根据当前字符决定调用哪个具体的解析函数
-func (p *parser) parseJSON() (interface{}, error) {
+func (p *Parser) parseJSON() (interface{}, error) {
 	p.skipWhitespace()
+	p.reportProgress()
+	if value, emitted, _ := p.applyRules(); emitted {
+		return value, nil
+	}
 	char, ok := p.getChar(0)
 	if !ok {
 		return nil, nil
 	}
 
+	if expr, ok := p.parseCallExpression(); ok {
+		return expr, nil
+	}
+	if placeholder, ok := p.parsePlaceholder(); ok {
+		return placeholder, nil
+	}
+
 	switch {
 	case char == '{':
 		p.index++
+		if _, wantObject := p.expected.(map[string]interface{}); !wantObject && !p.noSetLiterals && p.isSetLiteral() {
+			p.addDiagnostic(KindSetLiteral, SeverityInfo, p.index-1, 1,
+				"braced collection without keys converted to an array", "use '[' and ']' for arrays")
+			return p.parseSequence('}')
+		}
 		return p.parseObject()
 	case char == '[':
 		p.index++
 		return p.parseArray()
 	case char == '"' || char == '\'':
 		return p.parseString()
+	case char == '`' && p.context.inside():
+		// 顶层的反引号通常是 Markdown 代码块标记，不作为字符串处理
+		return p.parseString()
 	case unicode.IsDigit(char) || char == '-':
 		return p.parseNumber()
 	case char == 't' || char == 'f' || char == 'n':
@@ -122,32 +647,111 @@ func (p *parser) parseJSON() (interface{}, error) {
 			return p.parseString()
 		}
 	}
-	// 如果所有情况都不匹配，则前进一个字符并重试，以跳过垃圾字符
-	p.index++
+	// 如果所有情况都不匹配，则跳过垃圾字符后重试
+	p.skipGarbage()
+	if c, ok := p.getChar(0); p.garbageStrategy != SkipCharacter && p.context.inside() && (!ok || strings.ContainsRune(",}]", c)) {
+		// 跳过的内容占据了整个值的位置，交给容器处理分隔符或结束符
+		return nil, errNoValue
+	}
 	return p.parseJSON()
 }
 
+// parseCallExpression 在值的位置识别 datetime.datetime(2024, 1, 1)、Decimal('1.5')
+// 这类构造函数表达式，并将括号平衡的整个表达式作为字符串返回
+func (p *Parser) parseCallExpression() (string, bool) {
+	ctx, inCtx := p.context.current()
+	if !inCtx || (ctx != inObjectValue && ctx != inArray) {
+		return "", false
+	}
+
+	i := p.index
+	for i < len(p.jsonStr) && (unicode.IsLetter(p.jsonStr[i]) || p.jsonStr[i] == '_' ||
+		(i > p.index && (unicode.IsDigit(p.jsonStr[i]) || p.jsonStr[i] == '.'))) {
+		i++
+	}
+	if i == p.index || i >= len(p.jsonStr) || p.jsonStr[i] != '(' {
+		return "", false
+	}
+
+	depth := 0
+	var quote rune
+	for ; i < len(p.jsonStr); i++ {
+		char := p.jsonStr[i]
+		if quote != 0 {
+			if char == '\\' {
+				i++
+			} else if char == quote {
+				quote = 0
+			}
+			continue
+		}
+		switch char {
+		case '"', '\'':
+			quote = char
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth == 0 {
+				start := p.index
+				p.index = i + 1
+				p.addDiagnostic(KindCallExpression, SeverityInfo, start, p.index-start,
+					"constructor expression kept as a string", "serialize the value as a JSON string")
+				return string(p.jsonStr[start:p.index]), true
+			}
+		}
+	}
+	// 括号未闭合时不作处理，交给后续的解析逻辑
+	return "", false
+}
+
 // parseObject 解析一个JSON对象
-func (p *parser) parseObject() (map[string]interface{}, error) {
+func (p *Parser) parseObject() (map[string]interface{}, error) {
 	obj := make(map[string]interface{})
+	start := p.index - 1
 	p.context.push(inObjectKey)
 	defer p.context.pop()
 
+	shape, _ := p.expected.(map[string]interface{})
+	outerShape := p.objectShape
+	p.objectShape = shape
+	defer func() { p.objectShape = outerShape }()
+
+	var unitMembers map[string]string // UnitsSplit 写入的单位成员的键到对应数值成员的键
+	trailingComma := -1
 	for {
 		p.skipWhitespace()
 		char, ok := p.getChar(0)
 		if !ok || char == '}' {
+			if ok && trailingComma >= 0 {
+				p.addDiagnostic(KindExtraComma, SeverityInfo, trailingComma, 1,
+					"trailing comma before closing brace", "remove the comma")
+			}
 			break
 		}
 
 		if char == ',' {
+			p.addDiagnostic(KindExtraComma, SeverityInfo, p.index, 1,
+				"redundant comma in object", "remove the comma")
 			p.index++
 			continue
 		}
+		trailingComma = -1
 
 		// 解析键
 		p.context.stack[len(p.context.stack)-1] = inObjectKey
-		key, err := p.parseString()
+		ruleKey, emitted, consumed := p.applyRules()
+		if consumed && !emitted {
+			// 规则只消费了输入，重新检查对象是否结束
+			continue
+		}
+		var key string
+		var err error
+		if emitted {
+			key = fmt.Sprint(ruleKey)
+		} else {
+			key, err = p.parseString()
+		}
 		if err != nil {
 			// 如果键解析失败，可能是因为对象结束了
 			p.skipWhitespace()
@@ -158,81 +762,271 @@ func (p *parser) parseObject() (map[string]interface{}, error) {
 			p.index++
 			continue
 		}
+		p.attachComments(key, false)
 
 		p.skipWhitespace()
-		if c, ok := p.getChar(0); ok && c == ':' {
+		c, ok := p.getChar(0)
+		isFlag := p.flagKeys && (!ok || c == ',' || c == '}')
+		switch {
+		case ok && c == ':':
 			p.index++
+		case isFlag:
+			p.addDiagnostic(KindFlagKey, SeverityInfo, p.index, 0,
+				fmt.Sprintf("key %q has no value and was treated as a flag", key), "write the flag as \"key\": true")
+		default:
+			p.addDiagnostic(KindMissingColon, SeverityWarning, p.index, 0,
+				"missing colon after object key", "insert ':' after the key")
 		}
 
-		// 解析值
+		// 解析值；没有值的键（后面紧跟逗号或右括号）按 WithUnparseableValue 处理，使用 WithFlagKeys 时没有冒号的键取 true
 		p.context.stack[len(p.context.stack)-1] = inObjectValue
-		value, err := p.parseJSON()
-		if err != nil {
-			value = ""
+		p.expected = shape[key]
+		var value interface{} = true
+		keep := true
+		p.skipWhitespace()
+		valueStart := p.index
+		leave, wanted := p.enterPath(pathToken{key: key})
+		if c, ok := p.getChar(0); ok && c != ',' && c != '}' {
+			var err error
+			if !wanted {
+				p.skipValue()
+			} else if value, err = p.parseJSON(); err != nil {
+				value, keep = p.unparseableValue(key, valueStart)
+			}
+		} else if !isFlag && wanted {
+			value, keep = p.unparseableValue(key, valueStart)
+		}
+		leave()
+		if keep && wanted {
+			if base, ok := unitMembers[key]; ok {
+				// 真实成员与之前写入的单位成员同名，单位改用带后缀的键
+				unit := obj[key].(string)
+				delete(unitMembers, key)
+				obj[key] = value
+				unitMembers[p.addUnitMember(obj, base, unit)] = base
+			} else {
+				obj[key] = value
+			}
+		}
+		p.attachComments(key, true)
+		if p.pendingUnit != "" {
+			if unitMembers == nil {
+				unitMembers = make(map[string]string)
+			}
+			unitMembers[p.addUnitMember(obj, key, p.pendingUnit)] = key
+			p.pendingUnit = ""
 		}
-		obj[key] = value
 
 		p.skipWhitespace()
-		if c, ok := p.getChar(0); ok && c == ',' {
+		if c, ok := p.getChar(0); ok && (c == ',' || c == ';') {
+			p.checkSemicolon(c)
+			trailingComma = p.index
 			p.index++
 		} else if ok && c == '}' {
 			// 找到结束符，可以中断循环
 			break
+		} else if ok {
+			p.addMissingComma("object members", "member")
 		}
 	}
 
 	if char, ok := p.getChar(0); ok && char == '}' {
 		p.index++
+	} else {
+		p.addDiagnostic(KindUnclosedObject, SeverityWarning, start, p.index-start,
+			"object was never closed", "append '}' at the end of the object")
 	}
 	return obj, nil
 }
 
+// isSetLiteral 向前查看当前的花括号结构，如果它已闭合、非空且顶层没有冒号，
+// 则认为它是类似 Python 集合 {1, 2, 3} 的字面量
+// 使用 WithFlagKeys 且顶层成员都是字符串或标识符时，例如 {"verbose", dry_run}，按只有键的对象处理
+func (p *Parser) isSetLiteral() bool {
+	depth := 0
+	hasContent := false
+	keysOnly := true
+	member := memberEmpty
+	var quote rune
+	for i := p.index; i < len(p.jsonStr); i++ {
+		char := p.jsonStr[i]
+		if quote != 0 {
+			if char == '\\' {
+				i++
+			} else if char == quote {
+				quote = 0
+				if depth == 0 {
+					member = memberDone
+				}
+			}
+			continue
+		}
+		if depth == 0 && !unicode.IsSpace(char) && char != ',' && char != '}' {
+			// 每个顶层成员只能是一个字符串或一个标识符
+			identifier := char == '_' || unicode.IsLetter(char) || (member == memberIdentifier && unicode.IsDigit(char))
+			switch {
+			case member == memberEmpty && (char == '"' || char == '\''):
+			case member == memberEmpty && identifier:
+				member = memberIdentifier
+			case member == memberIdentifier && identifier:
+			default:
+				keysOnly = false
+			}
+		} else if depth == 0 && member == memberIdentifier && unicode.IsSpace(char) {
+			member = memberDone
+		}
+		switch {
+		case char == '"' || char == '\'':
+			quote = char
+		case char == '{' || char == '[':
+			depth++
+		case char == ']':
+			depth--
+		case char == '}':
+			if depth == 0 {
+				return hasContent && !(p.flagKeys && keysOnly)
+			}
+			depth--
+		case char == ',' && depth == 0:
+			member = memberEmpty
+		case char == ':' && depth == 0:
+			return false
+		}
+		if !unicode.IsSpace(char) {
+			hasContent = true
+		}
+	}
+	return false
+}
+
+// setMember 是 isSetLiteral 扫描顶层成员时的状态
+type setMember int
+
+const (
+	memberEmpty      setMember = iota // 成员尚未开始
+	memberIdentifier                  // 正在读取标识符
+	memberDone                        // 字符串或标识符已结束
+)
+
 // parseArray 解析一个JSON数组
-func (p *parser) parseArray() ([]interface{}, error) {
+func (p *Parser) parseArray() ([]interface{}, error) {
+	return p.parseSequence(']')
+}
+
+// parseSequence 解析以 closing 结尾的元素序列，数组和集合字面量共用此逻辑
+func (p *Parser) parseSequence(closing rune) ([]interface{}, error) {
 	arr := make([]interface{}, 0)
+	start := p.index - 1
 	p.context.push(inArray)
 	defer p.context.pop()
 
-	for {
+	var elemShape interface{}
+	if shape, ok := p.expected.([]interface{}); ok && len(shape) > 0 {
+		elemShape = shape[0]
+	}
+
+	// index 只在读到一个元素时增加，多余的逗号和右括号不占用下标，使筛选路径与结果中的下标一致
+	trailingComma, dropped := -1, 0
+	for index := 0; ; {
 		p.skipWhitespace()
 		char, ok := p.getChar(0)
-		if !ok || char == ']' {
+		if !ok || char == closing {
+			if ok && trailingComma >= 0 {
+				p.addDiagnostic(KindExtraComma, SeverityInfo, trailingComma, 1,
+					"trailing comma before closing bracket", "remove the comma")
+			}
 			break
 		}
 
 		if char == ',' { // 跳过多余的逗号
+			p.addDiagnostic(KindExtraComma, SeverityInfo, p.index, 1,
+				"redundant comma in array", "remove the comma")
 			p.index++
 			continue
 		}
+		trailingComma = -1
 
-		value, err := p.parseJSON()
+		p.expected = elemShape
+		leave, wanted := p.enterPath(pathToken{index: index, isIndex: true})
+		full := p.maxArrayLength > 0 && len(arr) >= p.maxArrayLength
+		var value interface{}
+		var err error
+		if wanted && !full {
+			value, err = p.parseJSON()
+		} else if !p.skipValue() {
+			// 跳过的是多余的右括号等内容而不是一个元素
+			leave()
+			continue
+		}
+		leave()
 		if err != nil {
 			// 如果解析失败，可能是数组结束了
 			p.skipWhitespace()
-			if c, ok := p.getChar(0); ok && c == ']' {
+			if c, ok := p.getChar(0); ok && c == closing {
 				break
 			}
 			p.index++
 			continue
 		}
-		arr = append(arr, value)
+		index++
+		switch {
+		case !wanted:
+			// 被筛选掉的元素不计入数组
+		case full:
+			dropped++
+		default:
+			arr = append(arr, value)
+		}
 
 		p.skipWhitespace()
-		if c, ok := p.getChar(0); ok && c == ',' {
+		if c, ok := p.getChar(0); ok && (c == ',' || c == ';') {
+			p.checkSemicolon(c)
+			trailingComma = p.index
 			p.index++
-		} else if ok && c == ']' {
+		} else if ok && c == closing {
 			break
+		} else if ok {
+			p.addMissingComma("array elements", "element")
 		}
 	}
 
-	if char, ok := p.getChar(0); ok && char == ']' {
+	if char, ok := p.getChar(0); ok && char == closing {
 		p.index++
+	} else {
+		p.addDiagnostic(KindUnclosedArray, SeverityWarning, start, p.index-start,
+			"array was never closed", "append '"+string(closing)+"' at the end of the array")
+	}
+	if dropped > 0 {
+		p.addDiagnostic(KindTruncatedArray, SeverityWarning, start, p.index-start,
+			fmt.Sprintf("kept the first %d elements, dropped %d", p.maxArrayLength, dropped),
+			"check the model output for a generation loop")
 	}
 	return arr, nil
 }
 
+// checkSemicolon 将出现在逗号位置上的分号记录为修复
+func (p *Parser) checkSemicolon(c rune) {
+	if c == ';' {
+		p.addDiagnostic(KindSemicolonSeparator, SeverityInfo, p.index, 1,
+			"semicolon used as a separator", "replace ';' with ','")
+	}
+}
+
+// addMissingComma 记录缺失的逗号，两个值之间隔着换行时视为隐式逗号
+func (p *Parser) addMissingComma(between, next string) {
+	for i := p.index - 1; i >= 0 && unicode.IsSpace(p.jsonStr[i]); i-- {
+		if p.jsonStr[i] == '\n' {
+			p.addDiagnostic(KindNewlineSeparator, SeverityInfo, p.index, 0,
+				"newline used as a separator between "+between, "insert ',' before the next "+next)
+			return
+		}
+	}
+	p.addDiagnostic(KindMissingComma, SeverityWarning, p.index, 0,
+		"missing comma between "+between, "insert ',' before the next "+next)
+}
+
 // parseString 解析一个JSON字符串
-func (p *parser) parseString() (string, error) {
+func (p *Parser) parseString() (string, error) {
 	p.skipWhitespace()
 	var startQuote rune
 	char, ok := p.getChar(0)
@@ -240,9 +1034,20 @@ func (p *parser) parseString() (string, error) {
 		return "", nil
 	}
 
+	start := p.index
 	missingQuotes := false
-	if char == '"' || char == '\'' {
+	if char == '"' || char == '\'' || char == '`' {
+		if char != '`' && p.isTripleQuote(char, 0) {
+			return p.parseTripleQuoted(char), nil
+		}
 		startQuote = char
+		if char == '\'' {
+			p.addDiagnostic(KindSingleQuotes, SeverityInfo, start, 1,
+				"string uses single quotes", "use double quotes")
+		} else if char == '`' {
+			p.addDiagnostic(KindBacktickQuotes, SeverityInfo, start, 1,
+				"string uses backticks", "use double quotes")
+		}
 		p.index++
 	} else {
 		missingQuotes = true
@@ -262,23 +1067,7 @@ func (p *parser) parseString() (string, error) {
 			if !nextOk {
 				break // 转义符在末尾
 			}
-			switch nextChar {
-			case '"', '\\', '/', '\'':
-				sb.WriteRune(nextChar)
-			case 'b':
-				sb.WriteRune('\b')
-			case 'f':
-				sb.WriteRune('\f')
-			case 'n':
-				sb.WriteRune('\n')
-			case 'r':
-				sb.WriteRune('\r')
-			case 't':
-				sb.WriteRune('\t')
-			default:
-				sb.WriteRune('\\')
-				sb.WriteRune(nextChar)
-			}
+			writeEscape(&sb, nextChar)
 			p.index++
 			continue
 		}
@@ -291,12 +1080,27 @@ func (p *parser) parseString() (string, error) {
 
 		// 如果引号缺失，需要根据上下文决定何时结束
 		if missingQuotes {
+			// 空白之后的注释是未加引号内容的边界，例如 name: foo // 说明
+			if p.atComment() && p.index > start && unicode.IsSpace(p.jsonStr[p.index-1]) {
+				break
+			}
 			ctx, inCtx := p.context.current()
+			if inCtx && p.objectShape != nil && unicode.IsSpace(char) {
+				// 骨架中已知的键可以作为未加引号内容的边界
+				if ctx == inObjectKey && p.isShapeKey(sb.String()) {
+					break
+				}
+				if ctx == inObjectValue && p.shapeKeyAhead() {
+					break
+				}
+			}
 			if inCtx {
-				if ctx == inObjectKey && char == ':' {
+				// 键可以包含点号、连字符和空格，一直读到冒号；逗号、右括号和换行不会出现在键中
+				if ctx == inObjectKey && (char == ':' || char == ',' || char == '}' || char == '\n') {
 					break
 				}
-				if (ctx == inObjectValue || ctx == inArray) && (char == ',' || char == '}' || char == ']') {
+				// 换行同样视为值的结束，后面的内容作为下一个元素或成员
+				if (ctx == inObjectValue || ctx == inArray) && (char == ',' || char == ';' || char == '}' || char == ']' || char == '\n') {
 					break
 				}
 			} else if char == ',' || char == '}' || char == ']' || char == ':' {
@@ -311,39 +1115,148 @@ func (p *parser) parseString() (string, error) {
 
 	// 对于未加引号的字符串，修剪尾部空格
 	if missingQuotes {
+		p.addDiagnostic(KindMissingQuotes, SeverityWarning, start, p.index-start,
+			"string is missing quotes", "wrap the value in double quotes")
 		return strings.TrimRight(sb.String(), " \t\n\r"), nil
 	}
+	p.addDiagnostic(KindUnclosedString, SeverityWarning, start, p.index-start,
+		"string was never closed", "append the closing quote")
 	return sb.String(), nil
 }
 
+// isTripleQuote 判断当前索引加偏移处是否为连续三个 quote 字符
+func (p *Parser) isTripleQuote(quote rune, offset int) bool {
+	for i := 0; i < 3; i++ {
+		if c, ok := p.getChar(offset + i); !ok || c != quote {
+			return false
+		}
+	}
+	return true
+}
+
+// parseTripleQuoted 解析由三个双引号或三个单引号包围的字符串（例如 """..."""），内部内容原样保留
+func (p *Parser) parseTripleQuoted(quote rune) string {
+	start := p.index
+	p.index += 3
+	contentStart := p.index
+	for p.index < len(p.jsonStr) {
+		if p.isTripleQuote(quote, 0) {
+			content := string(p.jsonStr[contentStart:p.index])
+			p.index += 3
+			p.addDiagnostic(KindTripleQuotes, SeverityInfo, start, p.index-start,
+				"string uses triple quotes", "use a double-quoted string with escaped newlines")
+			return content
+		}
+		p.index++
+	}
+	p.addDiagnostic(KindUnclosedString, SeverityWarning, start, p.index-start,
+		"string was never closed", "append the closing quotes")
+	return string(p.jsonStr[contentStart:])
+}
+
+// writeEscape 将转义序列 '\\' + c 解码后写入 sb，无法识别的转义原样保留
+func writeEscape(sb *strings.Builder, c rune) {
+	switch c {
+	case '"', '\\', '/', '\'', '`':
+		sb.WriteRune(c)
+	case 'b':
+		sb.WriteRune('\b')
+	case 'f':
+		sb.WriteRune('\f')
+	case 'n':
+		sb.WriteRune('\n')
+	case 'r':
+		sb.WriteRune('\r')
+	case 't':
+		sb.WriteRune('\t')
+	default:
+		sb.WriteRune('\\')
+		sb.WriteRune(c)
+	}
+}
+
 // parseNumber 解析一个数字
-func (p *parser) parseNumber() (interface{}, error) {
+func (p *Parser) parseNumber() (interface{}, error) {
+	start := p.index
 	var sb strings.Builder
 	for {
 		char, ok := p.getChar(0)
 		if !ok || (!unicode.IsDigit(char) && char != '.' && char != '-' && char != 'e' && char != 'E') {
-			break
+			// 指数部分可以带正号，例如 1e+5
+			if !ok || char != '+' || sb.Len() == 0 || !strings.ContainsAny(sb.String()[sb.Len()-1:], "eE") {
+				break
+			}
 		}
 		sb.WriteRune(char)
 		p.index++
 	}
 	numStr := sb.String()
+	if p.decimalComma {
+		numStr = p.parseDecimalComma(start, numStr)
+	}
+	if p.units != UnitsIgnore {
+		if value, ok := p.parseUnit(start, numStr); ok {
+			return value, nil
+		}
+	}
+	if value, ok := p.completeNumber(start, numStr); ok {
+		return value, nil
+	}
+	return p.numberValue(start, numStr), nil
+}
+
+// numberValue 将数字文本转换为 int64 或 float64
+func (p *Parser) numberValue(start int, numStr string) interface{} {
 	if strings.Contains(numStr, ".") || strings.Contains(numStr, "e") || strings.Contains(numStr, "E") {
 		f, err := strconv.ParseFloat(numStr, 64)
 		if err != nil {
-			return numStr, nil // 如果转换失败，则作为字符串返回
+			p.addInvalidNumber(start)
+			return numStr // 如果转换失败，则作为字符串返回
 		}
-		return f, nil
+		return f
 	}
 	i, err := strconv.ParseInt(numStr, 10, 64)
 	if err != nil {
-		return numStr, nil // 如果转换失败，则作为字符串返回
+		p.addInvalidNumber(start)
+		return numStr // 如果转换失败，则作为字符串返回
 	}
-	return i, nil
+	return i
+}
+
+// parseDecimalComma 读取紧跟在数字后面的逗号小数部分，返回转换为点号小数的数字文本
+// 与逗号小数同时出现的点号视为千位分隔符
+func (p *Parser) parseDecimalComma(start int, numStr string) string {
+	if c, ok := p.getChar(0); !ok || c != ',' || strings.ContainsAny(numStr, "eE") {
+		return numStr
+	}
+	if c, ok := p.getChar(1); !ok || !unicode.IsDigit(c) {
+		return numStr
+	}
+	p.index++
+	var sb strings.Builder
+	sb.WriteString(strings.ReplaceAll(numStr, ".", ""))
+	sb.WriteByte('.')
+	for {
+		char, ok := p.getChar(0)
+		if !ok || !unicode.IsDigit(char) {
+			break
+		}
+		sb.WriteRune(char)
+		p.index++
+	}
+	p.addDiagnostic(KindDecimalComma, SeverityInfo, start, p.index-start,
+		"decimal comma converted to a decimal point", "use '.' as the decimal separator")
+	return sb.String()
+}
+
+// addInvalidNumber 记录无法转换为数字的数值
+func (p *Parser) addInvalidNumber(start int) {
+	p.addDiagnostic(KindInvalidNumber, SeverityError, start, p.index-start,
+		"malformed number kept as a string", "fix the number literal")
 }
 
 // parseBooleanOrNull 解析 true, false, 或 null
-func (p *parser) parseBooleanOrNull() (interface{}, error) {
+func (p *Parser) parseBooleanOrNull() (interface{}, error) {
 	if strings.HasPrefix(string(p.jsonStr[p.index:]), "true") {
 		p.index += 4
 		return true, nil
@@ -378,6 +1291,11 @@ func (c *jsonContext) pop() {
 	}
 }
 
+// inside 判断解析器当前是否位于对象或数组内部
+func (c *jsonContext) inside() bool {
+	return len(c.stack) > 0
+}
+
 func (c *jsonContext) current() (contextValue, bool) {
 	if len(c.stack) == 0 {
 		return 0, false
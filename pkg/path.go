@@ -0,0 +1,140 @@
+package pkg
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// pathToken 是值路径中的一段，wildcard 表示 * 通配
+type pathToken struct {
+	key      string
+	index    int
+	isIndex  bool
+	wildcard bool
+}
+
+// pathPattern 是编译后的路径表达式，例如 $.items[*].price
+type pathPattern []pathToken
+
+// compilePathPattern 解析 $.a.b、$.items[0]、$["a b"]、$.items[*].price 形式的路径表达式
+func compilePathPattern(pattern string) (pathPattern, error) {
+	tokens, err := parsePath(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid path %q: %w", pattern, err)
+	}
+	return tokens, nil
+}
+
+// match 判断具体的值路径是否与表达式匹配
+func (pp pathPattern) match(path string) bool {
+	tokens, err := parsePath(path)
+	if err != nil {
+		return false
+	}
+	return pp.matchTokens(tokens)
+}
+
+func (pp pathPattern) matchTokens(tokens []pathToken) bool {
+	if len(tokens) != len(pp) {
+		return false
+	}
+	for i, want := range pp {
+		got := tokens[i]
+		switch {
+		case want.wildcard:
+		case want.isIndex != got.isIndex:
+			return false
+		case want.isIndex && want.index != got.index:
+			return false
+		case !want.isIndex && want.key != got.key:
+			return false
+		}
+	}
+	return true
+}
+
+// matchPrefix 判断路径是否与表达式开头的若干段匹配，即路径的后代可能与表达式匹配
+func (pp pathPattern) matchPrefix(tokens []pathToken) bool {
+	return len(tokens) < len(pp) && pp[:len(tokens)].matchTokens(tokens)
+}
+
+// parsePath 将路径字符串拆分为路径片段
+func parsePath(path string) ([]pathToken, error) {
+	if !strings.HasPrefix(path, "$") {
+		return nil, fmt.Errorf("path must start with '$'")
+	}
+	tokens := make([]pathToken, 0)
+	rest := path[1:]
+	for rest != "" {
+		switch rest[0] {
+		case '.':
+			end := strings.IndexAny(rest[1:], ".[")
+			if end < 0 {
+				end = len(rest) - 1
+			}
+			name := rest[1 : end+1]
+			if name == "" {
+				return nil, fmt.Errorf("empty key at %q", rest)
+			}
+			tokens = append(tokens, pathToken{key: name, wildcard: name == "*"})
+			rest = rest[end+1:]
+		case '[':
+			end := closingBracket(rest)
+			if end < 0 {
+				return nil, fmt.Errorf("unclosed '[' at %q", rest)
+			}
+			inner := rest[1:end]
+			switch {
+			case inner == "*":
+				tokens = append(tokens, pathToken{wildcard: true})
+			case strings.HasPrefix(inner, "\"") || strings.HasPrefix(inner, "'"):
+				key, err := unquotePathKey(inner)
+				if err != nil {
+					return nil, err
+				}
+				tokens = append(tokens, pathToken{key: key})
+			default:
+				i, err := strconv.Atoi(inner)
+				if err != nil || i < 0 {
+					return nil, fmt.Errorf("invalid index %q", inner)
+				}
+				tokens = append(tokens, pathToken{index: i, isIndex: true})
+			}
+			rest = rest[end+1:]
+		default:
+			return nil, fmt.Errorf("unexpected character at %q", rest)
+		}
+	}
+	return tokens, nil
+}
+
+// closingBracket 返回与开头的 '[' 对应的 ']' 的位置，跳过引号内的内容
+func closingBracket(s string) int {
+	var quote byte
+	for i := 1; i < len(s); i++ {
+		switch {
+		case quote != 0 && s[i] == '\\':
+			i++
+		case quote != 0 && s[i] == quote:
+			quote = 0
+		case quote == 0 && (s[i] == '"' || s[i] == '\''):
+			quote = s[i]
+		case quote == 0 && s[i] == ']':
+			return i
+		}
+	}
+	return -1
+}
+
+// unquotePathKey 解码方括号中带引号的键
+func unquotePathKey(s string) (string, error) {
+	if strings.HasPrefix(s, "'") {
+		s = "\"" + strings.ReplaceAll(strings.Trim(s, "'"), "\"", "\\\"") + "\""
+	}
+	key, err := strconv.Unquote(s)
+	if err != nil {
+		return "", fmt.Errorf("invalid quoted key %s", s)
+	}
+	return key, nil
+}
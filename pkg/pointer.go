@@ -0,0 +1,161 @@
+package pkg
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// RepairPointer 修复JSON并返回 RFC 6901 JSON Pointer（例如 "/data/items/0/id"）指向的值
+func RepairPointer(jsonStr, pointer string, opts ...Option) (interface{}, error) {
+	tokens, err := parsePointer(pointer)
+	if err != nil {
+		return nil, err
+	}
+	value, err := Loads(jsonStr, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	current := value
+	for i, token := range tokens {
+		current, err = pointerChild(current, token)
+		if err != nil {
+			return nil, fmt.Errorf("json pointer %q: %s: %w", pointer, pointerPrefix(tokens[:i+1]), err)
+		}
+	}
+	return current, nil
+}
+
+// RepairSetPointer 修复JSON，将 pointer 指向的位置设置为 value，并返回格式化的 JSON 字符串
+// 父级必须已经存在；对数组使用 "-" 或等于长度的下标时追加元素
+func RepairSetPointer(jsonStr, pointer string, value interface{}, opts ...Option) (string, error) {
+	tokens, err := parsePointer(pointer)
+	if err != nil {
+		return "", err
+	}
+	root, err := Loads(jsonStr, opts...)
+	if err != nil {
+		return "", err
+	}
+
+	root, err = setPointer(root, tokens, value)
+	if err != nil {
+		return "", fmt.Errorf("json pointer %q: %w", pointer, err)
+	}
+	repaired, err := json.MarshalIndent(root, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal repaired json: %w", err)
+	}
+	return string(repaired), nil
+}
+
+// parsePointer 将 JSON Pointer 拆分为解码后的引用片段
+func parsePointer(pointer string) ([]string, error) {
+	if pointer == "" {
+		return nil, nil
+	}
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, fmt.Errorf("invalid json pointer %q: must be empty or start with '/'", pointer)
+	}
+	tokens := strings.Split(pointer[1:], "/")
+	for i, token := range tokens {
+		tokens[i] = strings.NewReplacer("~1", "/", "~0", "~").Replace(token)
+	}
+	return tokens, nil
+}
+
+// pointerPrefix 将引用片段重新编码为 JSON Pointer，用于错误信息
+func pointerPrefix(tokens []string) string {
+	var sb strings.Builder
+	for _, token := range tokens {
+		sb.WriteByte('/')
+		sb.WriteString(strings.NewReplacer("~", "~0", "/", "~1").Replace(token))
+	}
+	return sb.String()
+}
+
+// pointerChild 返回 value 中由 token 引用的子值
+func pointerChild(value interface{}, token string) (interface{}, error) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		child, ok := v[token]
+		if !ok {
+			return nil, fmt.Errorf("key %q not found", token)
+		}
+		return child, nil
+	case []interface{}:
+		i, err := arrayIndex(token, len(v))
+		if err != nil {
+			return nil, err
+		}
+		if i >= len(v) {
+			return nil, fmt.Errorf("index %d out of range", i)
+		}
+		return v[i], nil
+	}
+	return nil, fmt.Errorf("cannot index into %T", value)
+}
+
+// setPointer 在 value 中沿引用片段设置新值，返回更新后的根值
+func setPointer(value interface{}, tokens []string, newValue interface{}) (interface{}, error) {
+	if len(tokens) == 0 {
+		return newValue, nil
+	}
+	token := tokens[0]
+	switch v := value.(type) {
+	case map[string]interface{}:
+		if len(tokens) == 1 {
+			v[token] = newValue
+			return v, nil
+		}
+		child, ok := v[token]
+		if !ok {
+			return nil, fmt.Errorf("key %q not found", token)
+		}
+		updated, err := setPointer(child, tokens[1:], newValue)
+		if err != nil {
+			return nil, err
+		}
+		v[token] = updated
+		return v, nil
+	case []interface{}:
+		i, err := arrayIndex(token, len(v))
+		if err != nil {
+			return nil, err
+		}
+		if i == len(v) {
+			if len(tokens) != 1 {
+				return nil, fmt.Errorf("index %d out of range", i)
+			}
+			return append(v, newValue), nil
+		}
+		updated, err := setPointer(v[i], tokens[1:], newValue)
+		if err != nil {
+			return nil, err
+		}
+		v[i] = updated
+		return v, nil
+	}
+	return nil, fmt.Errorf("cannot index into %T", value)
+}
+
+// arrayIndex 解析数组下标，"-" 表示末尾之后的位置
+func arrayIndex(token string, length int) (int, error) {
+	if token == "-" {
+		return length, nil
+	}
+	// RFC 6901 只允许 0 或不以 0 开头的十进制数字，不接受符号
+	if token == "" || strings.Trim(token, "0123456789") != "" || (len(token) > 1 && token[0] == '0') {
+		return 0, fmt.Errorf("invalid array index %q", token)
+	}
+	i, err := strconv.Atoi(token)
+	if err != nil {
+		return 0, fmt.Errorf("invalid array index %q", token)
+	}
+	if i > length {
+		return 0, fmt.Errorf("index %d out of range", i)
+	}
+	return i, nil
+}
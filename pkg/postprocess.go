@@ -0,0 +1,254 @@
+package pkg
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// postProcess 在解析完成后对结果执行需要按值处理的选项，合法 JSON 与修复后的结果都会经过这里
+func (p *Parser) postProcess(value interface{}) (interface{}, error) {
+	value, err := p.applyScalarPolicy(value)
+	if err != nil {
+		return nil, err
+	}
+	if (len(p.includeKeys) > 0 || len(p.excludeKeys) > 0) && !p.filtered {
+		value = p.filterValue(value, nil, false)
+	}
+	if p.skeleton != nil {
+		value = p.coerceToShape(value, p.skeleton, "$")
+	}
+	if p.maxStringLength > 0 || p.maxArrayLength > 0 || p.normalize || p.keyCase != KeyCaseKeep || p.sanitizeKeys || p.unflatten || len(p.transforms) > 0 || len(p.redactKeys) > 0 {
+		value = p.walkValue(value, "$")
+	}
+	if err := p.checkOutputLimits(value); err != nil {
+		return nil, err
+	}
+	return value, nil
+}
+
+// walkValue 递归处理一个值，path 为该值的路径
+func (p *Parser) walkValue(value interface{}, path string) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		if p.unflatten {
+			var changed bool
+			var sparse []string
+			if v, changed, sparse = unflattenObject(v); changed {
+				p.addPathDiagnostic(KindUnflattenedKeys, SeverityInfo, path,
+					"dot-notation keys were expanded into nested values", "emit nested objects instead of flattened keys")
+			}
+			for _, key := range sparse {
+				p.addPathDiagnostic(KindSparseFlatKey, SeverityWarning, childPath(path, key),
+					fmt.Sprintf("key %q has an array index too large to expand and was kept flattened", key),
+					"number array elements from 0 without gaps")
+			}
+		}
+		// 在键被规范化或改写之前按原始键标记需要屏蔽的值，改名后的键不会绕过 WithRedactKeys
+		p.markRedacted(v)
+		if p.normalize {
+			v = p.normalizeObjectKeys(v, path)
+		}
+		if p.keyCase != KeyCaseKeep {
+			v = p.convertObjectKeys(v, path)
+		}
+		if p.sanitizeKeys {
+			v = p.sanitizeObjectKeys(v, path)
+		}
+		for _, key := range sortedKeys(v) {
+			if _, marked := v[key].(redactedMark); marked || p.isRedactedKey(key) {
+				v[key] = RedactedValue
+				continue
+			}
+			v[key] = p.walkValue(v[key], childPath(path, key))
+		}
+		value = v
+	case []interface{}:
+		v = p.truncateArray(v, path)
+		for i, child := range v {
+			v[i] = p.walkValue(child, indexPath(path, i))
+		}
+		value = v
+	case string:
+		if p.normalize {
+			v = p.normForm.String(v)
+		}
+		value = p.truncateString(v, path)
+	}
+	return p.applyTransforms(value, path)
+}
+
+// RedactedValue 是被 WithRedactKeys 屏蔽的值的替代文本
+const RedactedValue = "[REDACTED]"
+
+// redactedMark 在改写键的过程中占据被屏蔽的值的位置，最后替换为 RedactedValue
+type redactedMark struct{}
+
+// markRedacted 将 obj 中按原始键需要屏蔽的值替换为 redactedMark
+func (p *Parser) markRedacted(obj map[string]interface{}) {
+	if len(p.redactKeys) == 0 {
+		return
+	}
+	for key := range obj {
+		if p.isRedactedKey(key) {
+			obj[key] = redactedMark{}
+		}
+	}
+}
+
+// isRedactedKey 判断键是否需要屏蔽，比较时忽略大小写
+func (p *Parser) isRedactedKey(key string) bool {
+	_, ok := p.redactKeys[strings.ToLower(key)]
+	return ok
+}
+
+// valueTransform 是通过 WithTransform 注册的按路径转换函数
+type valueTransform struct {
+	pattern pathPattern
+	fn      func(v interface{}) interface{}
+}
+
+// applyTransforms 对路径匹配的值依次调用转换函数
+func (p *Parser) applyTransforms(value interface{}, path string) interface{} {
+	for _, t := range p.transforms {
+		if t.pattern.match(path) {
+			value = t.fn(value)
+		}
+	}
+	return value
+}
+
+// truncateString 按 WithMaxStringLength 截断过长的字符串
+func (p *Parser) truncateString(s string, path string) string {
+	if p.maxStringLength <= 0 || len(s) <= p.maxStringLength {
+		return s
+	}
+	runes := []rune(s)
+	if len(runes) <= p.maxStringLength {
+		return s
+	}
+	p.addPathDiagnostic(KindTruncatedString, SeverityWarning, path,
+		fmt.Sprintf("string value exceeded %d characters and was truncated", p.maxStringLength),
+		"check the model output for a generation loop")
+	return string(runes[:p.maxStringLength]) + "…"
+}
+
+// truncateArray 按 WithMaxArrayElements 截断过长的数组，修复路径和 decodeLimited 在解码时已经截断
+func (p *Parser) truncateArray(arr []interface{}, path string) []interface{} {
+	if p.maxArrayLength <= 0 || len(arr) <= p.maxArrayLength {
+		return arr
+	}
+	p.addTruncatedArray(path, len(arr)-p.maxArrayLength)
+	return arr[:p.maxArrayLength]
+}
+
+// addTruncatedArray 记录 path 处的数组丢弃了 dropped 个超出上限的元素
+func (p *Parser) addTruncatedArray(path string, dropped int) {
+	p.addPathDiagnostic(KindTruncatedArray, SeverityWarning, path,
+		fmt.Sprintf("kept the first %d elements, dropped %d", p.maxArrayLength, dropped),
+		"check the model output for a generation loop")
+}
+
+// sanitizeObjectKeys 将对象的键改写为合法标识符，重名时追加 _2、_3 等后缀
+func (p *Parser) sanitizeObjectKeys(obj map[string]interface{}, path string) map[string]interface{} {
+	keys := make([]string, 0, len(obj))
+	for key := range obj {
+		keys = append(keys, key)
+	}
+	// 已经合法的键优先保留原名，其余按字典序处理以保证结果稳定
+	sort.Slice(keys, func(i, j int) bool {
+		vi, vj := isIdentifier(keys[i]), isIdentifier(keys[j])
+		if vi != vj {
+			return vi
+		}
+		return keys[i] < keys[j]
+	})
+
+	out := make(map[string]interface{}, len(obj))
+	for _, key := range keys {
+		name := sanitizeIdentifier(key)
+		for i := 2; ; i++ {
+			if _, exists := out[name]; !exists {
+				break
+			}
+			name = sanitizeIdentifier(key) + "_" + strconv.Itoa(i)
+		}
+		out[name] = obj[key]
+		if name != key {
+			if p.keyMapping != nil {
+				p.keyMapping.record(key, name)
+			}
+			p.addPathDiagnostic(KindSanitizedKey, SeverityInfo, childPath(path, key),
+				fmt.Sprintf("key %q was renamed to %q", key, name), "use identifier-safe keys")
+		}
+	}
+	return out
+}
+
+// sanitizeIdentifier 将非 ASCII 字母、数字和下划线的字符替换为下划线，并保证不以数字开头
+func sanitizeIdentifier(key string) string {
+	var sb strings.Builder
+	for _, c := range key {
+		if c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9') {
+			sb.WriteRune(c)
+		} else {
+			sb.WriteByte('_')
+		}
+	}
+	name := sb.String()
+	if name == "" || (name[0] >= '0' && name[0] <= '9') {
+		name = "_" + name
+	}
+	return name
+}
+
+// sortedKeys 返回排序后的对象键，保证后处理产生的诊断信息顺序稳定
+func sortedKeys(obj map[string]interface{}) []string {
+	keys := make([]string, 0, len(obj))
+	for key := range obj {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// childPath 返回对象成员的路径，非标识符的键使用方括号表示
+func childPath(path, key string) string {
+	if isIdentifier(key) {
+		return path + "." + key
+	}
+	return path + "[" + strconv.Quote(key) + "]"
+}
+
+// indexPath 返回数组元素的路径
+func indexPath(path string, i int) string {
+	return path + "[" + strconv.Itoa(i) + "]"
+}
+
+// isIdentifier 判断键是否由字母、数字和下划线组成且不以数字开头
+func isIdentifier(key string) bool {
+	if key == "" {
+		return false
+	}
+	for i, c := range key {
+		if c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (i > 0 && c >= '0' && c <= '9') {
+			continue
+		}
+		return false
+	}
+	return true
+}
+
+// keyRecorder 记录 WithSanitizedKeys 的键映射，同一个选项创建的解析器共用它，因此写入需要加锁
+type keyRecorder struct {
+	mu      sync.Mutex
+	mapping map[string]string
+}
+
+func (r *keyRecorder) record(key, name string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.mapping[key] = name
+}
@@ -0,0 +1,42 @@
+package pkg
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// profiles 是按模型系列预设的选项组合
+var profiles = map[string][]Option{
+	// GPT 系列常把 JSON 放在 Markdown 代码块中（默认即会提取），前后附带说明文字，遇到无法识别的字符时跳到下一个结构字符
+	"openai": {WithGarbageStrategy(SkipToStructural)},
+	// Claude 常把 JSON 包在 <json>、<output> 等 XML 标签中，并在之前输出 <thinking> 等标签
+	"claude": {WithXMLTags(), WithGarbageStrategy(SkipToStructural)},
+	// DeepSeek 等中文模型常输出全角标点与中文引号
+	"deepseek": {WithFullWidthPunctuation(), WithGarbageStrategy(SkipToStructural)},
+}
+
+// WithProfile 应用按模型系列预设的选项组合，可选 "openai"、"claude"、"deepseek"（不区分大小写）
+// 其后传入的选项可以覆盖预设中的同类设置
+func WithProfile(name string) Option {
+	return func(p *Parser) {
+		opts, ok := profiles[strings.ToLower(name)]
+		if !ok {
+			p.optionErr = fmt.Errorf("unknown profile %q, available profiles: %s", name, strings.Join(Profiles(), ", "))
+			return
+		}
+		for _, opt := range opts {
+			opt(p)
+		}
+	}
+}
+
+// Profiles 返回 WithProfile 支持的预设名称，按字典序排列
+func Profiles() []string {
+	names := make([]string, 0, len(profiles))
+	for name := range profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
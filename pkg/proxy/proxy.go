@@ -0,0 +1,363 @@
+// Package proxy 提供一个转发到 OpenAI 兼容接口的反向代理，
+// 它会修复响应中的工具调用参数（以及可选的消息内容），使客户端始终收到合法的 JSON
+package proxy
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/qdxiao/llmjsonrepair/pkg"
+)
+
+// Handler 是修复 LLM 响应的反向代理
+type Handler struct {
+	proxy         *httputil.ReverseProxy
+	repairOptions []pkg.Option
+	repairContent bool
+}
+
+// Option 配置 Handler
+type Option func(h *Handler)
+
+// WithRepairOptions 设置修复 JSON 时使用的选项
+func WithRepairOptions(opts ...pkg.Option) Option {
+	return func(h *Handler) {
+		h.repairOptions = append(h.repairOptions, opts...)
+	}
+}
+
+// WithContentRepair 同时修复消息内容，适用于要求模型以 JSON 格式回答的场景
+func WithContentRepair() Option {
+	return func(h *Handler) {
+		h.repairContent = true
+	}
+}
+
+// WithTransport 设置访问上游时使用的 http.RoundTripper
+func WithTransport(rt http.RoundTripper) Option {
+	return func(h *Handler) {
+		h.proxy.Transport = rt
+	}
+}
+
+// New 创建一个转发到 upstream（例如 https://api.openai.com）的代理
+func New(upstream string, opts ...Option) (*Handler, error) {
+	target, err := url.Parse(upstream)
+	if err != nil {
+		return nil, fmt.Errorf("invalid upstream url %q: %w", upstream, err)
+	}
+
+	h := &Handler{}
+	h.proxy = &httputil.ReverseProxy{
+		Rewrite: func(pr *httputil.ProxyRequest) {
+			pr.SetURL(target)
+			pr.SetXForwarded()
+			// 需要读取未压缩的响应体才能改写
+			pr.Out.Header.Del("Accept-Encoding")
+		},
+		ModifyResponse: h.modifyResponse,
+		FlushInterval:  -1,
+	}
+	for _, opt := range opts {
+		opt(h)
+	}
+	return h, nil
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.proxy.ServeHTTP(w, r)
+}
+
+// modifyResponse 根据响应类型改写流式或非流式的响应体
+func (h *Handler) modifyResponse(resp *http.Response) error {
+	mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	switch mediaType {
+	case "text/event-stream":
+		resp.Body = h.rewriteStream(resp.Body)
+		resp.Header.Del("Content-Length")
+		resp.ContentLength = -1
+	case "application/json":
+		data, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return fmt.Errorf("failed to read upstream response: %w", err)
+		}
+		data = h.rewriteCompletion(data)
+		resp.Body = io.NopCloser(bytes.NewReader(data))
+		resp.ContentLength = int64(len(data))
+		resp.Header.Set("Content-Length", strconv.Itoa(len(data)))
+	}
+	return nil
+}
+
+// rewriteCompletion 修复非流式响应中每个 message 的参数和内容
+func (h *Handler) rewriteCompletion(data []byte) []byte {
+	chunk, ok := decodeObject(data)
+	if !ok {
+		return data
+	}
+	for _, choice := range objects(chunk["choices"]) {
+		message, ok := choice["message"].(map[string]interface{})
+		if !ok {
+			continue
+		}
+		for _, fn := range functionObjects(message) {
+			if args, ok := fn["arguments"].(string); ok {
+				fn["arguments"] = h.repair(args)
+			}
+		}
+		if content, ok := message["content"].(string); ok && h.repairContent {
+			message["content"] = h.repair(content)
+		}
+	}
+	out, err := json.Marshal(chunk)
+	if err != nil {
+		return data
+	}
+	return out
+}
+
+// repair 将片段修复为紧凑的 JSON 字符串，失败时原样返回
+func (h *Handler) repair(s string) string {
+	if strings.TrimSpace(s) == "" {
+		return s
+	}
+	value, err := pkg.Loads(s, h.repairOptions...)
+	if err != nil {
+		return s
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return s
+	}
+	return string(data)
+}
+
+// rewriteStream 在后台改写 SSE 流，返回供代理读取的新响应体
+func (h *Handler) rewriteStream(body io.ReadCloser) io.ReadCloser {
+	pr, pw := io.Pipe()
+	go func() {
+		defer body.Close()
+		s := &streamRewriter{handler: h, pending: make(map[deltaKey]*strings.Builder)}
+		pw.CloseWithError(s.run(bufio.NewReader(body), pw))
+	}()
+	return pr
+}
+
+// deltaKey 标识一个需要累积的增量字段：某个 choice 中的某个工具调用或消息内容
+type deltaKey struct {
+	choice  string
+	tool    string
+	content bool
+}
+
+// streamRewriter 缓存参数和内容的增量，在 choice 结束时一次性发出修复后的完整值
+type streamRewriter struct {
+	handler  *Handler
+	pending  map[deltaKey]*strings.Builder
+	order    []deltaKey
+	envelope map[string]interface{}
+}
+
+func (s *streamRewriter) run(r *bufio.Reader, w io.Writer) error {
+	for {
+		line, err := r.ReadString('\n')
+		if line != "" {
+			if werr := s.processLine(line, w); werr != nil {
+				return werr
+			}
+		}
+		if errors.Is(err, io.EOF) {
+			return s.flush("", w)
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// processLine 改写一行 SSE 数据，非 data 行原样转发
+func (s *streamRewriter) processLine(line string, w io.Writer) error {
+	payload, isData := strings.CutPrefix(strings.TrimRight(line, "\r\n"), "data:")
+	if !isData {
+		_, err := io.WriteString(w, line)
+		return err
+	}
+	payload = strings.TrimSpace(payload)
+	if payload == "[DONE]" {
+		if err := s.flush("", w); err != nil {
+			return err
+		}
+		_, err := io.WriteString(w, line)
+		return err
+	}
+
+	chunk, ok := decodeObject([]byte(payload))
+	if !ok {
+		_, err := io.WriteString(w, line)
+		return err
+	}
+	s.envelope = chunk
+
+	for i, choice := range objects(chunk["choices"]) {
+		choiceIndex := indexOf(choice, i)
+		if delta, ok := choice["delta"].(map[string]interface{}); ok {
+			s.collect(choiceIndex, delta)
+		}
+		if reason, ok := choice["finish_reason"]; ok && reason != nil {
+			if err := s.flush(choiceIndex, w); err != nil {
+				return err
+			}
+		}
+	}
+
+	data, err := json.Marshal(chunk)
+	if err != nil {
+		_, err = io.WriteString(w, line)
+		return err
+	}
+	_, err = fmt.Fprintf(w, "data: %s\n", data)
+	return err
+}
+
+// collect 累积增量中的参数和内容，并将它们从转发的增量中清空
+func (s *streamRewriter) collect(choice string, delta map[string]interface{}) {
+	for i, call := range objects(delta["tool_calls"]) {
+		if fn, ok := call["function"].(map[string]interface{}); ok {
+			s.collectArguments(deltaKey{choice: choice, tool: indexOf(call, i)}, fn)
+		}
+	}
+	if fn, ok := delta["function_call"].(map[string]interface{}); ok {
+		s.collectArguments(deltaKey{choice: choice}, fn)
+	}
+	if content, ok := delta["content"].(string); ok && s.handler.repairContent {
+		s.append(deltaKey{choice: choice, content: true}, content)
+		delta["content"] = ""
+	}
+}
+
+// collectArguments 累积 fn 中的参数片段并将其清空
+func (s *streamRewriter) collectArguments(key deltaKey, fn map[string]interface{}) {
+	if args, ok := fn["arguments"].(string); ok {
+		s.append(key, args)
+		fn["arguments"] = ""
+	}
+}
+
+func (s *streamRewriter) append(key deltaKey, text string) {
+	sb, ok := s.pending[key]
+	if !ok {
+		sb = &strings.Builder{}
+		s.pending[key] = sb
+		s.order = append(s.order, key)
+	}
+	sb.WriteString(text)
+}
+
+// flush 为 choice（为空时表示全部）发出包含修复后完整值的增量事件
+func (s *streamRewriter) flush(choice string, w io.Writer) error {
+	remaining := s.order[:0]
+	for _, key := range s.order {
+		if choice != "" && key.choice != choice {
+			remaining = append(remaining, key)
+			continue
+		}
+		text := s.handler.repair(s.pending[key].String())
+		delete(s.pending, key)
+		data, err := json.Marshal(s.syntheticChunk(key, text))
+		if err != nil {
+			return fmt.Errorf("failed to marshal repaired chunk: %w", err)
+		}
+		if _, err := fmt.Fprintf(w, "data: %s\n\n", data); err != nil {
+			return err
+		}
+	}
+	s.order = remaining
+	return nil
+}
+
+// syntheticChunk 基于最近一个事件的公共字段构造只包含一个增量的事件
+func (s *streamRewriter) syntheticChunk(key deltaKey, text string) map[string]interface{} {
+	chunk := make(map[string]interface{})
+	for k, v := range s.envelope {
+		if k != "choices" && k != "usage" {
+			chunk[k] = v
+		}
+	}
+
+	delta := map[string]interface{}{}
+	if key.content {
+		delta["content"] = text
+	} else if key.tool == "" {
+		delta["function_call"] = map[string]interface{}{"arguments": text}
+	} else {
+		delta["tool_calls"] = []interface{}{map[string]interface{}{
+			"index":    json.Number(key.tool),
+			"function": map[string]interface{}{"arguments": text},
+		}}
+	}
+	chunk["choices"] = []interface{}{map[string]interface{}{
+		"index":         json.Number(key.choice),
+		"delta":         delta,
+		"finish_reason": nil,
+	}}
+	return chunk
+}
+
+// functionObjects 返回 message 或 delta 中所有包含 arguments 的对象：
+// tool_calls[].function 以及旧版的 function_call
+func functionObjects(m map[string]interface{}) []map[string]interface{} {
+	out := make([]map[string]interface{}, 0)
+	for _, call := range objects(m["tool_calls"]) {
+		if fn, ok := call["function"].(map[string]interface{}); ok {
+			out = append(out, fn)
+		}
+	}
+	if fn, ok := m["function_call"].(map[string]interface{}); ok {
+		out = append(out, fn)
+	}
+	return out
+}
+
+// indexOf 返回 choice 或工具调用的 index，缺失或不是数字时使用其在数组中的位置 i
+func indexOf(m map[string]interface{}, i int) string {
+	if index, ok := m["index"].(json.Number); ok {
+		if _, err := index.Int64(); err == nil {
+			return index.String()
+		}
+	}
+	return strconv.Itoa(i)
+}
+
+// objects 返回数组中所有对象元素
+func objects(v interface{}) []map[string]interface{} {
+	arr, _ := v.([]interface{})
+	out := make([]map[string]interface{}, 0, len(arr))
+	for _, item := range arr {
+		if obj, ok := item.(map[string]interface{}); ok {
+			out = append(out, obj)
+		}
+	}
+	return out
+}
+
+// decodeObject 解码 JSON 对象并保留数字的原始写法
+func decodeObject(data []byte) (map[string]interface{}, bool) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var obj map[string]interface{}
+	if err := dec.Decode(&obj); err != nil || obj == nil {
+		return nil, false
+	}
+	return obj, true
+}
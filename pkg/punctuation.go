@@ -0,0 +1,76 @@
+package pkg
+
+import "fmt"
+
+// fullWidthPunctuation 是字符串之外需要替换为 ASCII 的全角括号、冒号与逗号
+var fullWidthPunctuation = map[rune]rune{
+	'｛': '{',
+	'｝': '}',
+	'［': '[',
+	'］': ']',
+	'：': ':',
+	'，': ',',
+}
+
+// curlyClosing 是中文结束引号对应的 ASCII 引号
+var curlyClosing = map[rune]rune{
+	'”': '"',
+	'’': '\'',
+}
+
+// replaceFullWidth 将字符串之外的全角括号、冒号、逗号以及中文引号替换为对应的 ASCII 字符，
+// 字符串内部的全角标点保持不变；替换逐字符进行，偏移量与原输入一致
+// 返回的函数恢复原始输入，应在解析结束后调用
+func (p *Parser) replaceFullWidth() func() {
+	if !p.fullWidth {
+		return func() {}
+	}
+	original := p.jsonStr
+	var s []rune
+	first, last, count := 0, 0, 0
+	replace := func(i int, r rune) {
+		if s == nil {
+			s = append([]rune(nil), original...)
+			first = i
+		}
+		s[i] = r
+		last = i
+		count++
+	}
+
+	// closing 为当前字符串的结束引号，为 0 表示不在字符串内；中文引号开头的字符串只由对应的中文引号结束
+	var closing rune
+	for i := 0; i < len(original); i++ {
+		c := original[i]
+		switch {
+		case closing != 0 && c == '\\':
+			i++
+		case closing != 0:
+			if c == closing {
+				if r, ok := curlyClosing[c]; ok {
+					replace(i, r)
+				}
+				closing = 0
+			}
+		case c == '"' || c == '\'':
+			closing = c
+		case c == '“':
+			replace(i, '"')
+			closing = '”'
+		case c == '‘':
+			replace(i, '\'')
+			closing = '’'
+		default:
+			if r, ok := fullWidthPunctuation[c]; ok {
+				replace(i, r)
+			}
+		}
+	}
+	if s == nil {
+		return func() {}
+	}
+	p.addDiagnostic(KindFullWidthPunctuation, SeverityInfo, first, last-first+1,
+		fmt.Sprintf("replaced %d full-width punctuation characters", count), "use ASCII punctuation outside strings")
+	p.jsonStr = s
+	return func() { p.jsonStr = original }
+}
@@ -0,0 +1,333 @@
+package pkg
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// RepairStream 边读边修复 r 中的 JSON，并将修复后的紧凑 JSON 逐个记号写入 w，不构建对象和数组
+// 适用于更看重延迟和内存的代理场景；它只实现常见的修复：补全引号、逗号、冒号和括号，
+// 删除多余的逗号，将单引号和未加引号的字符串改为双引号字符串
+// 顶层对象和数组之外的内容会被跳过，每个顶层文档之后写入一个换行符；需要完整修复规则时使用 Repair
+// 字符串按块写出，内存占用与字符串长度无关；未加引号的单个键或值超过 16 MiB 时返回错误
+func RepairStream(r io.Reader, w io.Writer) error {
+	e := &streamEncoder{reader: bufio.NewReader(r), writer: w}
+	if err := e.run(); err != nil {
+		return err
+	}
+	return e.err
+}
+
+// frameState 是容器内部期待的下一个记号
+type frameState int
+
+const (
+	expectMember frameState = iota // 对象的键或数组的元素
+	expectColon                    // 对象的键之后
+	expectValue                    // 对象的冒号之后
+	expectComma                    // 成员或元素之后
+)
+
+// streamFrame 是一个尚未闭合的对象或数组
+type streamFrame struct {
+	object bool
+	state  frameState
+	count  int
+}
+
+// streamEncoder 保存重新编码过程中的状态
+type streamEncoder struct {
+	reader *bufio.Reader
+	writer io.Writer
+	stack  []*streamFrame
+	err    error // 第一个写入错误
+}
+
+func (e *streamEncoder) write(s string) {
+	if e.err == nil {
+		_, e.err = io.WriteString(e.writer, s)
+	}
+}
+
+func (e *streamEncoder) run() error {
+	for e.err == nil {
+		c, _, err := e.reader.ReadRune()
+		if errors.Is(err, io.EOF) {
+			e.closeAll()
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read input: %w", err)
+		}
+		if unicode.IsSpace(c) {
+			continue
+		}
+
+		if len(e.stack) == 0 {
+			// 在找到文档起点之前跳过其他内容
+			if c == '{' || c == '[' {
+				e.open(c)
+			}
+			continue
+		}
+
+		top := e.stack[len(e.stack)-1]
+		switch {
+		case c == '}' || c == ']':
+			e.close()
+		case c == ',':
+			if top.state == expectColon || top.state == expectValue {
+				e.write(missingValue(top.state))
+			}
+			top.state = expectMember
+		case c == ':':
+			if top.state == expectColon {
+				e.write(":")
+				top.state = expectValue
+			}
+		default:
+			if err := e.member(top, c); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// member 在容器中写入以 c 开头的键或值，必要时补上逗号或冒号
+func (e *streamEncoder) member(top *streamFrame, c rune) error {
+	if top.state == expectColon {
+		e.write(":")
+		top.state = expectValue
+	}
+	if top.state == expectComma {
+		top.state = expectMember
+	}
+	if top.state == expectMember {
+		if top.count > 0 {
+			e.write(",")
+		}
+		top.count++
+		if top.object {
+			top.state = expectColon
+			return e.key(c)
+		}
+	}
+	top.state = expectComma
+	return e.value(c)
+}
+
+// missingValue 返回补全缺失的冒号和值所需的文本
+func missingValue(state frameState) string {
+	if state == expectColon {
+		return `:""`
+	}
+	return `""`
+}
+
+func (e *streamEncoder) open(c rune) {
+	e.write(string(c))
+	e.stack = append(e.stack, &streamFrame{object: c == '{'})
+}
+
+func (e *streamEncoder) close() {
+	top := e.stack[len(e.stack)-1]
+	if top.state == expectColon || top.state == expectValue {
+		e.write(missingValue(top.state))
+	}
+	e.stack = e.stack[:len(e.stack)-1]
+	if top.object {
+		e.write("}")
+	} else {
+		e.write("]")
+	}
+	if len(e.stack) == 0 {
+		e.write("\n")
+	}
+}
+
+func (e *streamEncoder) closeAll() {
+	for len(e.stack) > 0 {
+		e.close()
+	}
+}
+
+// key 写入以 c 开头的对象键
+func (e *streamEncoder) key(c rune) error {
+	if c == '"' || c == '\'' {
+		return e.quoted(c)
+	}
+	word, err := e.bare(c, ":,}]\n")
+	if err != nil {
+		return err
+	}
+	e.write(strconv.Quote(word))
+	return nil
+}
+
+// value 写入以 c 开头的值
+func (e *streamEncoder) value(c rune) error {
+	switch {
+	case c == '{' || c == '[':
+		e.open(c)
+		return nil
+	case c == '"' || c == '\'':
+		return e.quoted(c)
+	}
+	stops := ",}]\n"
+	if c == '-' || unicode.IsDigit(c) {
+		// 数字在空白处结束，使 [1 2 3] 按缺少逗号处理
+		stops += " \t\r"
+	}
+	word, err := e.bare(c, stops)
+	if err != nil {
+		return err
+	}
+	switch {
+	case word == "true" || word == "false" || word == "null":
+		e.write(word)
+	case isJSONNumber(word):
+		e.write(word)
+	default:
+		e.write(strconv.Quote(word))
+	}
+	return nil
+}
+
+// quotedChunk 是 quoted 缓冲的最大字节数，超过后先写出已转换的部分，长字符串不会整体留在内存中
+const quotedChunk = 4096
+
+// quoted 读取以 quote 开头的字符串并以双引号字符串写出，流在字符串中间结束时补上引号
+func (e *streamEncoder) quoted(quote rune) error {
+	var sb strings.Builder
+	sb.WriteByte('"')
+	defer func() {
+		sb.WriteByte('"')
+		e.write(sb.String())
+	}()
+	for {
+		if sb.Len() >= quotedChunk {
+			e.write(sb.String())
+			sb.Reset()
+		}
+		c, _, err := e.reader.ReadRune()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read input: %w", err)
+		}
+		switch {
+		case c == quote:
+			return nil
+		case c == '\\':
+			next, _, err := e.reader.ReadRune()
+			if err != nil {
+				sb.WriteString(`\\`)
+				if errors.Is(err, io.EOF) {
+					return nil
+				}
+				return fmt.Errorf("failed to read input: %w", err)
+			}
+			switch next {
+			case '"', '\\', '/', 'b', 'f', 'n', 'r', 't':
+				sb.WriteRune('\\')
+				sb.WriteRune(next)
+			case 'u':
+				// 只有后面跟着四位十六进制数字时才是合法的 \u 转义，否则保留为字面的反斜杠和 u
+				if hex, _ := e.reader.Peek(4); len(hex) == 4 && isHex4(hex) {
+					sb.WriteString(`\u`)
+					sb.Write(hex)
+					e.reader.Discard(4)
+				} else {
+					sb.WriteString(`\\u`)
+				}
+			default:
+				writeStreamRune(&sb, next)
+			}
+		default:
+			writeStreamRune(&sb, c)
+		}
+	}
+}
+
+// isHex4 判断 b 是否全部为十六进制数字
+func isHex4(b []byte) bool {
+	for _, c := range b {
+		if !isHexDigit(c) {
+			return false
+		}
+	}
+	return true
+}
+
+// writeStreamRune 将字符串中的一个字符按 JSON 规则转义后写入
+func writeStreamRune(sb *strings.Builder, c rune) {
+	switch c {
+	case '"':
+		sb.WriteString(`\"`)
+	case '\\':
+		sb.WriteString(`\\`)
+	case '\n':
+		sb.WriteString(`\n`)
+	case '\r':
+		sb.WriteString(`\r`)
+	case '\t':
+		sb.WriteString(`\t`)
+	default:
+		if c < 0x20 {
+			fmt.Fprintf(sb, `\u%04x`, c)
+		} else {
+			sb.WriteRune(c)
+		}
+	}
+}
+
+// maxBareToken 是 RepairStream 中一个未加引号的键或值的最大字节数，需要读完才能判断它是数字、字面量还是字符串
+const maxBareToken = 16 << 20
+
+// bare 读取以 c 开头、直到 stops 中任一字符（不消耗该字符）的未加引号内容，并去掉首尾空白
+// 内容超过 maxBareToken 时返回错误，避免没有分隔符的输入被整体缓冲在内存中
+func (e *streamEncoder) bare(c rune, stops string) (string, error) {
+	var sb strings.Builder
+	sb.WriteRune(c)
+	for {
+		if sb.Len() > maxBareToken {
+			return "", fmt.Errorf("unquoted value exceeds %d bytes", maxBareToken)
+		}
+		next, _, err := e.reader.ReadRune()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return "", fmt.Errorf("failed to read input: %w", err)
+		}
+		if strings.ContainsRune(stops, next) {
+			_ = e.reader.UnreadRune()
+			break
+		}
+		sb.WriteRune(next)
+	}
+	return strings.TrimSpace(sb.String()), nil
+}
+
+// isJSONNumber 判断文本是否为合法的 JSON 数字
+func isJSONNumber(s string) bool {
+	if s == "" || strings.ContainsAny(s, "xX_") || s == "-" {
+		return false
+	}
+	if _, err := strconv.ParseFloat(s, 64); err != nil {
+		return false
+	}
+	// ParseFloat 还接受 inf、nan、前导 + 与多余的前导零，这些都不是 JSON 数字
+	t := strings.TrimPrefix(s, "-")
+	if t == "" || t[0] < '0' || t[0] > '9' || (len(t) > 1 && t[0] == '0' && t[1] != '.' && t[1] != 'e' && t[1] != 'E') {
+		return false
+	}
+	return t[len(t)-1] >= '0' && t[len(t)-1] <= '9'
+}
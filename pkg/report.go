@@ -0,0 +1,39 @@
+package pkg
+
+// Finding 是 Diagnostic 的 JSON 表示，字段名保持稳定，供编辑器插件与 CI 工具解析
+type Finding struct {
+	Rule       RepairKind `json:"rule"`
+	Severity   string     `json:"severity"`
+	Offset     int        `json:"offset"` // 字符（rune）偏移，后处理阶段产生的问题为 -1
+	Length     int        `json:"length"`
+	Line       int        `json:"line,omitempty"`
+	Column     int        `json:"column,omitempty"`
+	Path       string     `json:"path,omitempty"`
+	Message    string     `json:"message"`
+	Suggestion string     `json:"suggestion,omitempty"`
+}
+
+// Report 是一个输入的全部诊断信息，可以直接用 encoding/json 编码
+type Report struct {
+	File     string    `json:"file,omitempty"`
+	Findings []Finding `json:"findings"`
+}
+
+// NewReport 将 Diagnose 返回的诊断信息转换为 file 的报告
+func NewReport(file string, diagnostics []Diagnostic) Report {
+	report := Report{File: file, Findings: make([]Finding, 0, len(diagnostics))}
+	for _, d := range diagnostics {
+		report.Findings = append(report.Findings, Finding{
+			Rule:       d.Kind,
+			Severity:   d.Severity.String(),
+			Offset:     d.Offset,
+			Length:     d.Length,
+			Line:       d.Line,
+			Column:     d.Column,
+			Path:       d.Path,
+			Message:    d.Message,
+			Suggestion: d.Suggestion,
+		})
+	}
+	return report
+}
@@ -0,0 +1,102 @@
+package pkg
+
+import "fmt"
+
+// ContextKind 表示解析器当前所处的位置
+type ContextKind int
+
+const (
+	ContextTopLevel ContextKind = iota
+	ContextArray
+	ContextObjectKey
+	ContextObjectValue
+)
+
+// RuleContext 是调用自定义修复规则时提供的解析上下文
+type RuleContext struct {
+	Kind   ContextKind
+	Depth  int // 当前的对象/数组嵌套深度
+	Offset int // 当前位置在输入中的字符偏移
+}
+
+// RuleResult 是自定义修复规则的处理结果
+// Consumed 为 0 表示规则不处理当前位置；否则解析器跳过 Consumed 个字符，
+// Emit 为 true 时以 Value 作为当前位置的解析结果，否则继续解析后面的内容
+type RuleResult struct {
+	Consumed int
+	Value    interface{}
+	Emit     bool
+}
+
+// RepairRule 是用户自定义的修复规则，在解析器即将解析一个值或对象键之前调用
+// input 为从当前位置开始的剩余输入，规则不应修改它
+type RepairRule interface {
+	Name() string
+	Apply(ctx RuleContext, input []rune) RuleResult
+}
+
+// NewRepairRule 使用函数创建一个修复规则
+func NewRepairRule(name string, fn func(ctx RuleContext, input []rune) RuleResult) RepairRule {
+	return &funcRule{name: name, fn: fn}
+}
+
+type funcRule struct {
+	name string
+	fn   func(ctx RuleContext, input []rune) RuleResult
+}
+
+func (r *funcRule) Name() string {
+	return r.name
+}
+
+func (r *funcRule) Apply(ctx RuleContext, input []rune) RuleResult {
+	return r.fn(ctx, input)
+}
+
+// applyRules 依次尝试自定义规则，返回规则产出的值、是否产出了值以及是否消费了输入
+func (p *Parser) applyRules() (interface{}, bool, bool) {
+	consumed := false
+	for len(p.rules) > 0 {
+		p.skipWhitespace()
+		if p.index >= len(p.jsonStr) {
+			return nil, false, consumed
+		}
+
+		applied := false
+		for _, rule := range p.rules {
+			result := rule.Apply(p.ruleContext(), p.jsonStr[p.index:])
+			if result.Consumed <= 0 {
+				continue
+			}
+			n := min(result.Consumed, len(p.jsonStr)-p.index)
+			p.addDiagnostic(KindCustomRule, SeverityInfo, p.index, n,
+				fmt.Sprintf("repair rule %q was applied", rule.Name()), "")
+			p.index += n
+			if result.Emit {
+				return result.Value, true, true
+			}
+			applied, consumed = true, true
+			break
+		}
+		if !applied {
+			break
+		}
+	}
+	return nil, false, consumed
+}
+
+// ruleContext 根据解析器状态构造规则上下文
+func (p *Parser) ruleContext() RuleContext {
+	ctx := RuleContext{Kind: ContextTopLevel, Depth: len(p.context.stack), Offset: p.index}
+	if current, ok := p.context.current(); ok {
+		switch current {
+		case inArray:
+			ctx.Kind = ContextArray
+		case inObjectKey:
+			ctx.Kind = ContextObjectKey
+		case inObjectValue:
+			ctx.Kind = ContextObjectValue
+		}
+	}
+	return ctx
+}
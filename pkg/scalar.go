@@ -0,0 +1,59 @@
+package pkg
+
+import (
+	"errors"
+	"fmt"
+)
+
+// TopLevelScalarPolicy 决定修复结果的顶层是字符串、数字、布尔值或 null 时如何处理
+type TopLevelScalarPolicy struct {
+	reject bool
+	wrap   bool // 将标量包装为以 key 为键的对象
+	key    string
+}
+
+var (
+	// ScalarAllow 原样返回顶层标量（默认）
+	ScalarAllow = TopLevelScalarPolicy{}
+	// ScalarError 顶层为标量时返回 ErrTopLevelScalar
+	ScalarError = TopLevelScalarPolicy{reject: true}
+)
+
+// WrapInObject 将顶层标量包装为 {key: value}；key 不能为空，空键会使解析返回错误
+func WrapInObject(key string) TopLevelScalarPolicy {
+	return TopLevelScalarPolicy{wrap: true, key: key}
+}
+
+// ErrTopLevelScalar 表示修复结果的顶层不是对象或数组
+var ErrTopLevelScalar = errors.New("repaired value is not an object or array")
+
+// applyScalarPolicy 按 WithTopLevelScalar 处理顶层标量
+func (p *Parser) applyScalarPolicy(value interface{}) (interface{}, error) {
+	switch value.(type) {
+	case map[string]interface{}, []interface{}:
+		return value, nil
+	}
+	switch {
+	case p.scalarPolicy.reject:
+		return nil, fmt.Errorf("%w: got %s", ErrTopLevelScalar, scalarKind(value))
+	case p.scalarPolicy.wrap:
+		p.addPathDiagnostic(KindWrappedScalar, SeverityInfo, "$",
+			fmt.Sprintf("%s was wrapped in an object under %q", scalarKind(value), p.scalarPolicy.key),
+			"return an object")
+		return map[string]interface{}{p.scalarPolicy.key: value}, nil
+	}
+	return value, nil
+}
+
+// scalarKind 返回标量的 JSON 类型名
+func scalarKind(value interface{}) string {
+	switch value.(type) {
+	case nil:
+		return "null"
+	case string:
+		return "string"
+	case bool:
+		return "boolean"
+	}
+	return "number"
+}
@@ -0,0 +1,135 @@
+package pkg
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+)
+
+// InferSchema 修复JSON并生成描述其结构的 JSON Schema（draft 2020-12）
+// 对象出现的所有键都列为 required；数组元素的结构会合并，元素之间缺失的键不列为 required
+func InferSchema(jsonStr string, opts ...Option) ([]byte, error) {
+	value, err := Loads(jsonStr, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	shape := newShapeNode()
+	shape.add(value)
+	schema := shape.schema()
+	schema["$schema"] = "https://json-schema.org/draft/2020-12/schema"
+	data, err := json.MarshalIndent(schema, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal schema: %w", err)
+	}
+	return data, nil
+}
+
+// shapeNode 记录一个位置上出现过的所有值的类型与结构，用于合并多个样本
+type shapeNode struct {
+	types      map[string]bool
+	properties map[string]*shapeNode
+	keyCounts  map[string]int // 包含该键的对象数量
+	objects    int            // 合并过的对象数量
+	items      *shapeNode
+}
+
+func newShapeNode() *shapeNode {
+	return &shapeNode{
+		types:      make(map[string]bool),
+		properties: make(map[string]*shapeNode),
+		keyCounts:  make(map[string]int),
+	}
+}
+
+// add 将一个值合并到结构中
+func (n *shapeNode) add(value interface{}) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		n.types["object"] = true
+		n.objects++
+		for key, child := range v {
+			prop, ok := n.properties[key]
+			if !ok {
+				prop = newShapeNode()
+				n.properties[key] = prop
+			}
+			prop.add(child)
+			n.keyCounts[key]++
+		}
+	case []interface{}:
+		n.types["array"] = true
+		if n.items == nil {
+			n.items = newShapeNode()
+		}
+		for _, child := range v {
+			n.items.add(child)
+		}
+	case string:
+		n.types["string"] = true
+	case bool:
+		n.types["boolean"] = true
+	case nil:
+		n.types["null"] = true
+	case int64, int:
+		n.types["integer"] = true
+	case float64:
+		if v == math.Trunc(v) && !math.IsInf(v, 0) {
+			n.types["integer"] = true
+		} else {
+			n.types["number"] = true
+		}
+	default:
+		n.types["string"] = true
+	}
+}
+
+// required 返回在每个合并过的对象中都出现的键，按字典序排列
+func (n *shapeNode) required() []string {
+	keys := make([]string, 0, len(n.keyCounts))
+	for key, count := range n.keyCounts {
+		if count == n.objects {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// typeNames 返回出现过的类型，integer 与 number 同时出现时合并为 number
+func (n *shapeNode) typeNames() []string {
+	names := make([]string, 0, len(n.types))
+	for name := range n.types {
+		if name == "integer" && n.types["number"] {
+			continue
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// schema 将结构转换为 JSON Schema
+func (n *shapeNode) schema() map[string]interface{} {
+	schema := make(map[string]interface{})
+	switch names := n.typeNames(); len(names) {
+	case 0:
+	case 1:
+		schema["type"] = names[0]
+	default:
+		schema["type"] = names
+	}
+	if n.types["object"] {
+		props := make(map[string]interface{}, len(n.properties))
+		for key, prop := range n.properties {
+			props[key] = prop.schema()
+		}
+		schema["properties"] = props
+		schema["required"] = n.required()
+	}
+	if n.items != nil && len(n.items.types) > 0 {
+		schema["items"] = n.items.schema()
+	}
+	return schema
+}
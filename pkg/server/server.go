@@ -0,0 +1,158 @@
+// Package server 以 HTTP 服务的形式提供JSON修复，便于非 Go 服务调用
+//
+//	POST /repair  请求体为待修复的文本，返回格式化后的 JSON 字符串
+//	POST /loads   请求体为待修复的文本，返回修复后的 JSON 值
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/qdxiao/llmjsonrepair/pkg"
+)
+
+// defaultMaxBodyBytes 是请求体大小的默认上限
+const defaultMaxBodyBytes = 10 << 20
+
+// Server 是提供修复接口的 http.Handler
+type Server struct {
+	mux           *http.ServeMux
+	repairOptions []pkg.Option
+	maxBodyBytes  int64
+}
+
+// Option 配置 Server
+type Option func(s *Server)
+
+// WithRepairOptions 设置修复 JSON 时使用的选项
+func WithRepairOptions(opts ...pkg.Option) Option {
+	return func(s *Server) {
+		s.repairOptions = append(s.repairOptions, opts...)
+	}
+}
+
+// WithMaxBodyBytes 设置请求体大小上限，超出时返回 413
+func WithMaxBodyBytes(n int64) Option {
+	return func(s *Server) {
+		s.maxBodyBytes = n
+	}
+}
+
+// New 创建修复服务
+func New(opts ...Option) *Server {
+	s := &Server{
+		mux:          http.NewServeMux(),
+		maxBodyBytes: defaultMaxBodyBytes,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	s.mux.HandleFunc("POST /repair", s.handleRepair)
+	s.mux.HandleFunc("POST /loads", s.handleLoads)
+	return s
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+// Issue 是响应中的一条诊断信息
+type Issue struct {
+	Kind       pkg.RepairKind `json:"kind"`
+	Severity   string         `json:"severity"`
+	Offset     int            `json:"offset"`
+	Length     int            `json:"length"`
+	Line       int            `json:"line,omitempty"`
+	Column     int            `json:"column,omitempty"`
+	Path       string         `json:"path,omitempty"`
+	Message    string         `json:"message"`
+	Suggestion string         `json:"suggestion,omitempty"`
+}
+
+// Response 是两个接口共用的响应格式，Repaired 与 Value 只会设置其中之一
+type Response struct {
+	Repaired   *string     `json:"repaired,omitempty"`
+	Value      interface{} `json:"value,omitempty"`
+	Changed    bool        `json:"changed"`
+	Report     []Issue     `json:"report"`
+	Confidence float64     `json:"confidence"` // 输入中参与解析的比例，取值 0 到 1
+}
+
+func (s *Server) handleRepair(w http.ResponseWriter, r *http.Request) {
+	resp, value, ok := s.process(w, r)
+	if !ok {
+		return
+	}
+	repaired, err := json.MarshalIndent(value, "", "  ")
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, fmt.Errorf("failed to marshal repaired json: %w", err))
+		return
+	}
+	text := string(repaired)
+	resp.Repaired = &text
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func (s *Server) handleLoads(w http.ResponseWriter, r *http.Request) {
+	resp, value, ok := s.process(w, r)
+	if !ok {
+		return
+	}
+	resp.Value = value
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// process 读取请求体并修复，失败时已写入错误响应并返回 false
+func (s *Server) process(w http.ResponseWriter, r *http.Request) (*Response, interface{}, bool) {
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, s.maxBodyBytes))
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			writeError(w, http.StatusRequestEntityTooLarge, err)
+		} else {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("failed to read request body: %w", err))
+		}
+		return nil, nil, false
+	}
+
+	// 结果与报告来自同一个解析器，避免重复修复，也保证报告覆盖后处理产生的修改
+	parser := pkg.NewParser(string(body), s.repairOptions...)
+	value, err := parser.Load()
+	if err != nil {
+		writeError(w, http.StatusUnprocessableEntity, err)
+		return nil, nil, false
+	}
+
+	resp := &Response{
+		Changed:    parser.WasRepaired(),
+		Report:     make([]Issue, 0),
+		Confidence: parser.Stats().Coverage,
+	}
+	for _, d := range parser.Diagnostics() {
+		resp.Report = append(resp.Report, Issue{
+			Kind:       d.Kind,
+			Severity:   d.Severity.String(),
+			Offset:     d.Offset,
+			Length:     d.Length,
+			Line:       d.Line,
+			Column:     d.Column,
+			Path:       d.Path,
+			Message:    d.Message,
+			Suggestion: d.Suggestion,
+		})
+	}
+	return resp, value, true
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, map[string]string{"error": err.Error()})
+}
@@ -0,0 +1,144 @@
+package pkg
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// isShapeKey 判断 key 是否为骨架中当前对象的已知键
+func (p *Parser) isShapeKey(key string) bool {
+	_, ok := p.objectShape[key]
+	return ok
+}
+
+// shapeKeyAhead 判断跳过空白后，接下来的内容是否以骨架中当前对象的已知键开头
+func (p *Parser) shapeKeyAhead() bool {
+	i := p.index
+	for i < len(p.jsonStr) && unicode.IsSpace(p.jsonStr[i]) {
+		i++
+	}
+	rest := p.jsonStr[i:]
+	for key := range p.objectShape {
+		if hasKeyPrefix(rest, key) {
+			return true
+		}
+		if len(rest) > 0 && (rest[0] == '"' || rest[0] == '\'') && hasKeyPrefix(rest[1:], key) &&
+			len(rest) > len([]rune(key))+1 && rest[len([]rune(key))+1] == rest[0] {
+			return true
+		}
+	}
+	return false
+}
+
+// hasKeyPrefix 判断 rest 是否以完整的单词 key 开头
+func hasKeyPrefix(rest []rune, key string) bool {
+	keyRunes := []rune(key)
+	if len(keyRunes) == 0 || len(rest) < len(keyRunes) || string(rest[:len(keyRunes)]) != key {
+		return false
+	}
+	if len(rest) == len(keyRunes) {
+		return true
+	}
+	next := rest[len(keyRunes)]
+	return unicode.IsSpace(next) || next == ':' || next == '"' || next == '\''
+}
+
+// coerceToShape 按骨架转换值的类型，path 为值的路径
+func (p *Parser) coerceToShape(value, shape interface{}, path string) interface{} {
+	if value == nil || shape == nil {
+		return value
+	}
+
+	switch s := shape.(type) {
+	case map[string]interface{}:
+		obj, ok := value.(map[string]interface{})
+		if !ok {
+			return value
+		}
+		for key, child := range obj {
+			if childShape, ok := s[key]; ok {
+				obj[key] = p.coerceToShape(child, childShape, childPath(path, key))
+			}
+		}
+		return obj
+	case []interface{}:
+		arr, ok := value.([]interface{})
+		if !ok {
+			p.addShapeDiagnostic(path, "value was wrapped in an array to match the skeleton")
+			arr = []interface{}{value}
+		}
+		if len(s) > 0 {
+			for i, child := range arr {
+				arr[i] = p.coerceToShape(child, s[0], indexPath(path, i))
+			}
+		}
+		return arr
+	case string:
+		if text, ok := scalarText(value).(string); ok && text != value {
+			p.addShapeDiagnostic(path, "value was converted to a string to match the skeleton")
+			return text
+		}
+	case int64, float64:
+		if str, ok := value.(string); ok {
+			if n, ok := parseNumberText(str); ok {
+				p.addShapeDiagnostic(path, "string was converted to a number to match the skeleton")
+				return n
+			}
+		}
+	case bool:
+		if b, ok := parseBoolText(value); ok {
+			if _, isBool := value.(bool); !isBool {
+				p.addShapeDiagnostic(path, "value was converted to a boolean to match the skeleton")
+			}
+			return b
+		}
+	}
+	return value
+}
+
+func (p *Parser) addShapeDiagnostic(path, message string) {
+	p.addPathDiagnostic(KindShapeCoerced, SeverityInfo, path, message,
+		fmt.Sprintf("emit the value at %s with the expected type", path))
+}
+
+// parseNumberText 将符合 JSON 数字语法的字符串解析为 int64 或 float64，inf、NaN 等不会被接受
+func parseNumberText(s string) (interface{}, bool) {
+	s = strings.TrimSpace(s)
+	if !isJSONNumber(s) {
+		return nil, false
+	}
+	if i, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return i, true
+	}
+	if f, err := strconv.ParseFloat(s, 64); err == nil && !math.IsInf(f, 0) {
+		return f, true
+	}
+	return nil, false
+}
+
+// parseBoolText 将布尔值、"true"/"yes" 等字符串或 0/1 转换为布尔值
+func parseBoolText(value interface{}) (bool, bool) {
+	switch v := value.(type) {
+	case bool:
+		return v, true
+	case string:
+		switch strings.ToLower(strings.TrimSpace(v)) {
+		case "true", "yes", "y", "1":
+			return true, true
+		case "false", "no", "n", "0":
+			return false, true
+		}
+	case int64:
+		if v == 0 || v == 1 {
+			return v == 1, true
+		}
+	case float64:
+		if v == 0 || v == 1 {
+			return v == 1, true
+		}
+	}
+	return false, false
+}
@@ -0,0 +1,40 @@
+package pkg
+
+import (
+	"unicode"
+	"unicode/utf8"
+)
+
+// ParseStats 描述输入中有多少内容实际参与了解析结果
+// 统计时忽略空白字符，被当作垃圾字符跳过的内容计入 SkippedBytes
+type ParseStats struct {
+	TotalBytes   int     // 输入中非空白字符的字节数
+	SkippedBytes int     // 被跳过的非空白字符的字节数
+	Coverage     float64 // 参与解析的比例，取值 0 到 1
+}
+
+// Stats 返回本次解析的覆盖率统计
+func (p *Parser) Stats() ParseStats {
+	stats := ParseStats{}
+	for _, char := range p.jsonStr {
+		if !unicode.IsSpace(char) {
+			stats.TotalBytes += utf8.RuneLen(char)
+		}
+	}
+	for _, d := range p.diagnostics {
+		if d.Kind != KindSkippedGarbage {
+			continue
+		}
+		for i := d.Offset; i < d.Offset+d.Length && i < len(p.jsonStr); i++ {
+			if !unicode.IsSpace(p.jsonStr[i]) {
+				stats.SkippedBytes += utf8.RuneLen(p.jsonStr[i])
+			}
+		}
+	}
+
+	stats.Coverage = 1
+	if stats.TotalBytes > 0 {
+		stats.Coverage = float64(stats.TotalBytes-stats.SkippedBytes) / float64(stats.TotalBytes)
+	}
+	return stats
+}
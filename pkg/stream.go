@@ -0,0 +1,214 @@
+package pkg
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+	"strings"
+)
+
+// Values 从流中逐个读取顶层的对象或数组，修复后惰性地产出，适用于多个 JSON 文档首尾相连的流
+// 对象和数组之外的内容（例如模型输出的说明文字）会被跳过，流末尾被截断的文档会尽量修复后产出
+// WithCharset 与带 BOM 的 UTF-16 在切分文档之前作用于整个流，StreamInto 与 LogValues 同样如此
+func Values(r io.Reader, opts ...Option) iter.Seq2[interface{}, error] {
+	return func(yield func(interface{}, error) bool) {
+		r, opts := decodeStream(r, opts)
+		scanner := newDocumentScanner(r)
+		for {
+			doc, err := scanner.next()
+			if errors.Is(err, io.EOF) {
+				return
+			}
+			if err != nil {
+				yield(nil, err)
+				return
+			}
+			value, err := Loads(doc, opts...)
+			if !yield(value, err) {
+				return
+			}
+		}
+	}
+}
+
+// documentScanner 按括号深度将字符流切分为顶层文档
+type documentScanner struct {
+	reader *bufio.Reader
+}
+
+func newDocumentScanner(r io.Reader) *documentScanner {
+	return &documentScanner{reader: bufio.NewReader(r)}
+}
+
+// next 返回下一个顶层对象或数组的原始文本，流结束时返回 io.EOF
+func (s *documentScanner) next() (string, error) {
+	var sb strings.Builder
+	depth := 0
+	var quote rune
+	escaped := false
+	for {
+		char, _, err := s.reader.ReadRune()
+		if err != nil {
+			if errors.Is(err, io.EOF) && sb.Len() > 0 {
+				// 流在文档中间结束，交给修复程序处理
+				return sb.String(), nil
+			}
+			if errors.Is(err, io.EOF) {
+				return "", io.EOF
+			}
+			return "", fmt.Errorf("failed to read input: %w", err)
+		}
+
+		// 在找到文档起点之前跳过其他内容
+		if depth == 0 && char != '{' && char != '[' {
+			continue
+		}
+		sb.WriteRune(char)
+
+		if quote != 0 {
+			switch {
+			case escaped:
+				escaped = false
+			case char == '\\':
+				escaped = true
+			case char == quote:
+				quote = 0
+			}
+			continue
+		}
+		switch char {
+		case '"', '\'':
+			quote = char
+		case '{', '[':
+			depth++
+		case '}', ']':
+			depth--
+			if depth == 0 {
+				return sb.String(), nil
+			}
+		}
+	}
+}
+
+// StreamInto 修复流中的顶层数组（可以是被截断的），每读完一个元素就将其解码为 T 并发送到 out
+// 数组之前的内容会被跳过，数组结束或流结束时返回 nil；StreamInto 不会关闭 out
+// 元素无法解码为 T 时停止并返回错误，错误信息中包含元素的下标
+// 每个元素单独修复，WithSkeleton、WithIncludeKeys、WithExcludeKeys、WithTransform 与 WithTopLevelScalar
+// 这类相对于顶层值的选项无法按数组中的位置生效，与它们同时使用时直接返回错误
+func StreamInto[T any](r io.Reader, out chan<- T, opts ...Option) error {
+	if names := NewParser("", opts...).elementConflicts(); len(names) > 0 {
+		return fmt.Errorf("StreamInto cannot be combined with %s", strings.Join(names, ", "))
+	}
+	r, opts = decodeStream(r, opts)
+	scanner := newElementScanner(r)
+	for i := 0; ; i++ {
+		elem, err := scanner.next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		var v T
+		if err := Unmarshal(elem, &v, opts...); err != nil {
+			return fmt.Errorf("array element %d: %w", i, err)
+		}
+		out <- v
+	}
+}
+
+// elementConflicts 返回已配置但在逐个元素修复时无法按原路径生效的选项
+func (p *Parser) elementConflicts() []string {
+	var names []string
+	for _, option := range []struct {
+		name string
+		set  bool
+	}{
+		{"WithSkeleton", p.skeleton != nil},
+		{"WithIncludeKeys", len(p.includeKeys) > 0},
+		{"WithExcludeKeys", len(p.excludeKeys) > 0},
+		{"WithTransform", len(p.transforms) > 0},
+		{"WithTopLevelScalar", p.scalarPolicy != ScalarAllow},
+	} {
+		if option.set {
+			names = append(names, option.name)
+		}
+	}
+	return names
+}
+
+// elementScanner 将顶层数组切分为各个元素的原始文本
+type elementScanner struct {
+	reader  *bufio.Reader
+	started bool
+	done    bool
+}
+
+func newElementScanner(r io.Reader) *elementScanner {
+	return &elementScanner{reader: bufio.NewReader(r)}
+}
+
+// next 返回下一个非空元素的原始文本，数组或流结束时返回 io.EOF
+func (s *elementScanner) next() (string, error) {
+	var sb strings.Builder
+	depth := 0
+	var quote rune
+	escaped := false
+	for !s.done {
+		char, _, err := s.reader.ReadRune()
+		if errors.Is(err, io.EOF) {
+			// 流在数组中间结束，最后一个元素交给修复程序处理
+			s.done = true
+			break
+		}
+		if err != nil {
+			return "", fmt.Errorf("failed to read input: %w", err)
+		}
+
+		// 在找到数组起点之前跳过其他内容
+		if !s.started {
+			s.started = char == '['
+			continue
+		}
+
+		if quote != 0 {
+			switch {
+			case escaped:
+				escaped = false
+			case char == '\\':
+				escaped = true
+			case char == quote:
+				quote = 0
+			}
+			sb.WriteRune(char)
+			continue
+		}
+		switch char {
+		case '"', '\'':
+			quote = char
+		case '{', '[':
+			depth++
+		case '}', ']':
+			if depth == 0 {
+				s.done = true
+				continue
+			}
+			depth--
+		case ',':
+			if depth == 0 {
+				if elem := strings.TrimSpace(sb.String()); elem != "" {
+					return elem, nil
+				}
+				continue
+			}
+		}
+		sb.WriteRune(char)
+	}
+
+	if elem := strings.TrimSpace(sb.String()); elem != "" {
+		return elem, nil
+	}
+	return "", io.EOF
+}
@@ -0,0 +1,236 @@
+package pkg
+
+import (
+	"fmt"
+	"unicode/utf8"
+)
+
+// StrictCheckError 表示修复结果未通过 WithStrictCheck 的 RFC 8259 语法检查，说明修复程序自身存在缺陷
+type StrictCheckError struct {
+	Offset int // 问题在输出中的字节偏移
+	Reason string
+}
+
+func (e *StrictCheckError) Error() string {
+	return fmt.Sprintf("repaired output is not valid RFC 8259 JSON at byte %d: %s", e.Offset, e.Reason)
+}
+
+// checkRFC8259 按 RFC 8259 的语法严格检查 data 是否为单个 JSON 文本
+func checkRFC8259(data []byte) error {
+	v := &strictValidator{data: data}
+	v.whitespace()
+	if err := v.value(); err != nil {
+		return err
+	}
+	v.whitespace()
+	if v.pos < len(v.data) {
+		return v.fail("unexpected data after the top-level value")
+	}
+	return nil
+}
+
+// strictValidator 是 RFC 8259 语法的递归下降检查器
+type strictValidator struct {
+	data []byte
+	pos  int
+}
+
+func (v *strictValidator) fail(reason string) error {
+	return &StrictCheckError{Offset: v.pos, Reason: reason}
+}
+
+func (v *strictValidator) peek() (byte, bool) {
+	if v.pos >= len(v.data) {
+		return 0, false
+	}
+	return v.data[v.pos], true
+}
+
+// whitespace 跳过 RFC 8259 允许的四种空白字符
+func (v *strictValidator) whitespace() {
+	for v.pos < len(v.data) {
+		switch v.data[v.pos] {
+		case ' ', '\t', '\n', '\r':
+			v.pos++
+		default:
+			return
+		}
+	}
+}
+
+func (v *strictValidator) value() error {
+	c, ok := v.peek()
+	if !ok {
+		return v.fail("unexpected end of input")
+	}
+	switch {
+	case c == '{':
+		return v.object()
+	case c == '[':
+		return v.array()
+	case c == '"':
+		return v.string()
+	case c == '-' || (c >= '0' && c <= '9'):
+		return v.number()
+	}
+	for _, literal := range []string{"true", "false", "null"} {
+		if len(v.data)-v.pos >= len(literal) && string(v.data[v.pos:v.pos+len(literal)]) == literal {
+			v.pos += len(literal)
+			return nil
+		}
+	}
+	return v.fail(fmt.Sprintf("unexpected character %q", c))
+}
+
+func (v *strictValidator) object() error {
+	v.pos++
+	v.whitespace()
+	if c, ok := v.peek(); ok && c == '}' {
+		v.pos++
+		return nil
+	}
+	for {
+		v.whitespace()
+		if c, ok := v.peek(); !ok || c != '"' {
+			return v.fail("expected a string key")
+		}
+		if err := v.string(); err != nil {
+			return err
+		}
+		v.whitespace()
+		if c, ok := v.peek(); !ok || c != ':' {
+			return v.fail("expected ':' after object key")
+		}
+		v.pos++
+		v.whitespace()
+		if err := v.value(); err != nil {
+			return err
+		}
+		v.whitespace()
+		c, ok := v.peek()
+		switch {
+		case ok && c == ',':
+			v.pos++
+		case ok && c == '}':
+			v.pos++
+			return nil
+		default:
+			return v.fail("expected ',' or '}' in object")
+		}
+	}
+}
+
+func (v *strictValidator) array() error {
+	v.pos++
+	v.whitespace()
+	if c, ok := v.peek(); ok && c == ']' {
+		v.pos++
+		return nil
+	}
+	for {
+		v.whitespace()
+		if err := v.value(); err != nil {
+			return err
+		}
+		v.whitespace()
+		c, ok := v.peek()
+		switch {
+		case ok && c == ',':
+			v.pos++
+		case ok && c == ']':
+			v.pos++
+			return nil
+		default:
+			return v.fail("expected ',' or ']' in array")
+		}
+	}
+}
+
+func (v *strictValidator) string() error {
+	v.pos++
+	for {
+		c, ok := v.peek()
+		switch {
+		case !ok:
+			return v.fail("unterminated string")
+		case c == '"':
+			v.pos++
+			return nil
+		case c < 0x20:
+			return v.fail("unescaped control character in string")
+		case c == '\\':
+			v.pos++
+			esc, ok := v.peek()
+			if !ok {
+				return v.fail("unterminated escape sequence")
+			}
+			switch esc {
+			case '"', '\\', '/', 'b', 'f', 'n', 'r', 't':
+				v.pos++
+			case 'u':
+				v.pos++
+				for i := 0; i < 4; i++ {
+					h, ok := v.peek()
+					if !ok || !isHexDigit(h) {
+						return v.fail("invalid \\u escape")
+					}
+					v.pos++
+				}
+			default:
+				return v.fail(fmt.Sprintf("invalid escape character %q", esc))
+			}
+		case c < utf8.RuneSelf:
+			v.pos++
+		default:
+			r, size := utf8.DecodeRune(v.data[v.pos:])
+			if r == utf8.RuneError && size == 1 {
+				return v.fail("invalid UTF-8 in string")
+			}
+			v.pos += size
+		}
+	}
+}
+
+func (v *strictValidator) number() error {
+	if c, _ := v.peek(); c == '-' {
+		v.pos++
+	}
+	c, ok := v.peek()
+	switch {
+	case ok && c == '0':
+		v.pos++
+	case ok && c >= '1' && c <= '9':
+		v.digits()
+	default:
+		return v.fail("expected digit in number")
+	}
+	if c, ok := v.peek(); ok && c == '.' {
+		v.pos++
+		if v.digits() == 0 {
+			return v.fail("expected digit after decimal point")
+		}
+	}
+	if c, ok := v.peek(); ok && (c == 'e' || c == 'E') {
+		v.pos++
+		if c, ok := v.peek(); ok && (c == '+' || c == '-') {
+			v.pos++
+		}
+		if v.digits() == 0 {
+			return v.fail("expected digit in exponent")
+		}
+	}
+	return nil
+}
+
+// digits 跳过连续的十进制数字并返回其数量
+func (v *strictValidator) digits() int {
+	start := v.pos
+	for v.pos < len(v.data) && v.data[v.pos] >= '0' && v.data[v.pos] <= '9' {
+		v.pos++
+	}
+	return v.pos - start
+}
+
+func isHexDigit(c byte) bool {
+	return (c >= '0' && c <= '9') || (c >= 'a' && c <= 'f') || (c >= 'A' && c <= 'F')
+}
@@ -0,0 +1,48 @@
+package pkg
+
+// placeholderDelimiters 是 WithTemplatePlaceholders 识别的模板占位符的起止符号
+var placeholderDelimiters = [][2]string{
+	{"{{", "}}"},
+	{"${", "}"},
+}
+
+// parsePlaceholder 将当前位置未加引号的模板占位符（例如 {{user_name}}、${count}）原样读取为字符串
+func (p *Parser) parsePlaceholder() (string, bool) {
+	if !p.templates {
+		return "", false
+	}
+	for _, delim := range placeholderDelimiters {
+		if !p.hasPrefixAt(p.index, delim[0]) {
+			continue
+		}
+		end := p.index + len([]rune(delim[0]))
+		for end < len(p.jsonStr) && !p.hasPrefixAt(end, delim[1]) {
+			if p.jsonStr[end] == '\n' {
+				return "", false
+			}
+			end++
+		}
+		if end == len(p.jsonStr) {
+			return "", false
+		}
+		end += len([]rune(delim[1]))
+
+		start := p.index
+		p.index = end
+		p.addDiagnostic(KindTemplatePlaceholder, SeverityInfo, start, end-start,
+			"template placeholder kept as a string", "quote the placeholder")
+		return string(p.jsonStr[start:end]), true
+	}
+	return "", false
+}
+
+// hasPrefixAt 判断输入在偏移 i 处是否以 prefix 开头
+func (p *Parser) hasPrefixAt(i int, prefix string) bool {
+	for _, c := range prefix {
+		if i >= len(p.jsonStr) || p.jsonStr[i] != c {
+			return false
+		}
+		i++
+	}
+	return true
+}
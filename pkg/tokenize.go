@@ -0,0 +1,171 @@
+package pkg
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// TokenKind 表示词法单元的类型
+type TokenKind int
+
+const (
+	TokenInvalid TokenKind = iota
+	TokenObjectStart
+	TokenObjectEnd
+	TokenArrayStart
+	TokenArrayEnd
+	TokenColon
+	TokenComma
+	TokenString
+	TokenNumber
+	TokenBool
+	TokenNull
+)
+
+func (k TokenKind) String() string {
+	switch k {
+	case TokenObjectStart:
+		return "object_start"
+	case TokenObjectEnd:
+		return "object_end"
+	case TokenArrayStart:
+		return "array_start"
+	case TokenArrayEnd:
+		return "array_end"
+	case TokenColon:
+		return "colon"
+	case TokenComma:
+		return "comma"
+	case TokenString:
+		return "string"
+	case TokenNumber:
+		return "number"
+	case TokenBool:
+		return "bool"
+	case TokenNull:
+		return "null"
+	}
+	return "invalid"
+}
+
+// Token 是宽松词法分析产生的一个词法单元
+type Token struct {
+	Kind     TokenKind
+	Value    interface{} // 解码后的值：string、int64、float64、bool 或 nil
+	Start    int         // 在输入中的起始字节偏移
+	End      int         // 在输入中的结束字节偏移（不含）
+	Repaired bool        // 该词法单元是否经过修复，例如单引号、未闭合或缺少引号的字符串
+}
+
+// Tokenize 使用宽松的词法规则将输入切分为词法单元，不构建完整的解析树
+func Tokenize(jsonStr string) []Token {
+	tokens := make([]Token, 0)
+	i := 0
+	for i < len(jsonStr) {
+		char, size := utf8.DecodeRuneInString(jsonStr[i:])
+		if unicode.IsSpace(char) {
+			i += size
+			continue
+		}
+
+		start := i
+		var tok Token
+		switch {
+		case strings.ContainsRune("{}[]:,", char):
+			tok, i = Token{Kind: structuralKind(char)}, i+size
+		case char == '"' || char == '\'':
+			tok, i = lexString(jsonStr, i)
+		case ('0' <= char && char <= '9') || char == '-':
+			// lexNumber 只读取 ASCII 数字，其他数字字符按非法字符处理
+			tok, i = lexNumber(jsonStr, i)
+		case unicode.IsLetter(char) || char == '_':
+			tok, i = lexWord(jsonStr, i)
+		default:
+			tok, i = Token{Kind: TokenInvalid, Value: string(char), Repaired: true}, i+size
+		}
+		tok.Start, tok.End = start, i
+		tokens = append(tokens, tok)
+	}
+	return tokens
+}
+
+func structuralKind(char rune) TokenKind {
+	switch char {
+	case '{':
+		return TokenObjectStart
+	case '}':
+		return TokenObjectEnd
+	case '[':
+		return TokenArrayStart
+	case ']':
+		return TokenArrayEnd
+	case ':':
+		return TokenColon
+	}
+	return TokenComma
+}
+
+// lexString 读取一个带引号的字符串，返回词法单元和结束位置
+func lexString(s string, i int) (Token, int) {
+	quote := rune(s[i])
+	i++
+	var sb strings.Builder
+	for i < len(s) {
+		char, size := utf8.DecodeRuneInString(s[i:])
+		if char == '\\' {
+			i += size
+			if i >= len(s) {
+				break
+			}
+			next, nextSize := utf8.DecodeRuneInString(s[i:])
+			writeEscape(&sb, next)
+			i += nextSize
+			continue
+		}
+		i += size
+		if char == quote {
+			return Token{Kind: TokenString, Value: sb.String(), Repaired: quote != '"'}, i
+		}
+		sb.WriteRune(char)
+	}
+	return Token{Kind: TokenString, Value: sb.String(), Repaired: true}, i
+}
+
+// lexNumber 读取一个数字，无法转换时作为修复过的字符串返回
+func lexNumber(s string, i int) (Token, int) {
+	start := i
+	for i < len(s) && strings.IndexByte("0123456789.-eE", s[i]) >= 0 {
+		i++
+	}
+	numStr := s[start:i]
+	if strings.ContainsAny(numStr, ".eE") {
+		if f, err := strconv.ParseFloat(numStr, 64); err == nil {
+			return Token{Kind: TokenNumber, Value: f}, i
+		}
+	} else if n, err := strconv.ParseInt(numStr, 10, 64); err == nil {
+		return Token{Kind: TokenNumber, Value: n}, i
+	}
+	return Token{Kind: TokenString, Value: numStr, Repaired: true}, i
+}
+
+// lexWord 读取一个未加引号的单词，识别 true、false 和 null
+func lexWord(s string, i int) (Token, int) {
+	start := i
+	for i < len(s) {
+		char, size := utf8.DecodeRuneInString(s[i:])
+		if unicode.IsSpace(char) || strings.ContainsRune("{}[]:,\"'", char) {
+			break
+		}
+		i += size
+	}
+	switch word := s[start:i]; word {
+	case "true", "false":
+		return Token{Kind: TokenBool, Value: word == "true"}, i
+	case "null":
+		return Token{Kind: TokenNull}, i
+	default:
+		return Token{Kind: TokenString, Value: word, Repaired: true}, i
+	}
+}
@@ -0,0 +1,36 @@
+package pkg
+
+import "strings"
+
+// TruncatedNumberPolicy 决定输入在数字中间结束（例如 12. 或 1e）时如何补全
+type TruncatedNumberPolicy int
+
+const (
+	// TruncatedNumberTrim 去掉未写完的小数点、指数或符号，12. 补全为 12，1e 补全为 1（默认）
+	TruncatedNumberTrim TruncatedNumberPolicy = iota
+	// TruncatedNumberNull 将被截断的数字替换为 null
+	TruncatedNumberNull
+	// TruncatedNumberString 将被截断的数字原样作为字符串保留
+	TruncatedNumberString
+)
+
+// completeNumber 处理位于输入末尾且无法解析的数字，不是被截断的数字时返回 false
+func (p *Parser) completeNumber(start int, numStr string) (interface{}, bool) {
+	if p.index < len(p.jsonStr) {
+		return nil, false
+	}
+	trimmed := strings.TrimRight(numStr, ".eE+-")
+	if isJSONNumber(numStr) || !isJSONNumber(trimmed) {
+		return nil, false
+	}
+
+	p.addDiagnostic(KindTruncatedNumber, SeverityWarning, start, p.index-start,
+		"number was cut off at the end of the input", "complete the number")
+	switch p.truncatedNumbers {
+	case TruncatedNumberNull:
+		return nil, true
+	case TruncatedNumberString:
+		return numStr, true
+	}
+	return p.numberValue(start, trimmed), true
+}
@@ -0,0 +1,147 @@
+package pkg
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+var (
+	jsonUnmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// Unmarshal 修复JSON并将结果解码到 v 指向的结构中
+// 实现了 json.Unmarshaler 的字段会收到修复后的 JSON 片段；
+// 实现了 encoding.TextUnmarshaler 的字段在模型输出数字或布尔值时，会收到其文本形式
+func Unmarshal(jsonStr string, v interface{}, opts ...Option) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return fmt.Errorf("unmarshal target must be a non-nil pointer, got %T", v)
+	}
+
+	value, err := Loads(jsonStr, opts...)
+	if err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(normalizeForType(value, rv.Type().Elem()))
+	if err != nil {
+		return fmt.Errorf("failed to marshal repaired json: %w", err)
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("failed to decode repaired json: %w", err)
+	}
+	return nil
+}
+
+// normalizeForType 根据目标类型调整修复后的值，使其能被目标类型自身的解码逻辑接受
+func normalizeForType(value interface{}, t reflect.Type) interface{} {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	// 自定义 JSON 解码逻辑直接接收原始片段
+	if t.Implements(jsonUnmarshalerType) || reflect.PointerTo(t).Implements(jsonUnmarshalerType) {
+		return value
+	}
+	if t.Implements(textUnmarshalerType) || reflect.PointerTo(t).Implements(textUnmarshalerType) {
+		return scalarText(value)
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		obj, ok := value.(map[string]interface{})
+		if !ok {
+			return value
+		}
+		out := make(map[string]interface{}, len(obj))
+		for key, v := range obj {
+			if field, found := findField(t, key); found {
+				out[key] = normalizeForType(v, field.Type)
+			} else {
+				out[key] = v
+			}
+		}
+		return out
+	case reflect.Slice, reflect.Array:
+		arr, ok := value.([]interface{})
+		if !ok {
+			return value
+		}
+		out := make([]interface{}, len(arr))
+		for i, v := range arr {
+			out[i] = normalizeForType(v, t.Elem())
+		}
+		return out
+	case reflect.Map:
+		obj, ok := value.(map[string]interface{})
+		if !ok {
+			return value
+		}
+		out := make(map[string]interface{}, len(obj))
+		for key, v := range obj {
+			out[key] = normalizeForType(v, t.Elem())
+		}
+		return out
+	}
+	return value
+}
+
+// scalarText 将数字和布尔值转换为文本，供 encoding.TextUnmarshaler 使用
+func scalarText(value interface{}) interface{} {
+	switch v := value.(type) {
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	}
+	return value
+}
+
+// findField 按照 encoding/json 的规则查找与键对应的结构体字段
+func findField(t reflect.Type, key string) (reflect.StructField, bool) {
+	var fold reflect.StructField
+	foldFound := false
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, _, _ := strings.Cut(tag, ",")
+
+		// 未指定名称的匿名结构体字段会被展开
+		if field.Anonymous && name == "" {
+			ft := field.Type
+			if ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				if f, ok := findField(ft, key); ok {
+					return f, true
+				}
+				continue
+			}
+		}
+		if !field.IsExported() {
+			continue
+		}
+
+		if name == "" {
+			name = field.Name
+		}
+		if name == key {
+			return field, true
+		}
+		if !foldFound && strings.EqualFold(name, key) {
+			fold, foldFound = field, true
+		}
+	}
+	return fold, foldFound
+}
@@ -0,0 +1,75 @@
+package pkg
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// UnitPolicy 决定 3kg、250ms 这类带单位后缀的数字如何处理
+type UnitPolicy int
+
+const (
+	// UnitsIgnore 不识别单位，数字在字母处结束（默认）
+	UnitsIgnore UnitPolicy = iota
+	// UnitsAsString 将整个记号作为字符串保留，例如 "3kg"
+	UnitsAsString
+	// UnitsSplit 对象成员的值只保留数字部分，单位写入同级的 <key>_unit 成员；
+	// 数组元素等没有同级成员可写的位置仍作为字符串保留
+	UnitsSplit
+)
+
+// unitSuffix 是写入单位的同级成员的键后缀
+const unitSuffix = "_unit"
+
+// parseUnit 尝试读取紧跟在数字后面的单位后缀，没有单位时不消耗输入并返回 false
+func (p *Parser) parseUnit(start int, numStr string) (interface{}, bool) {
+	// "3em" 中的 e 已被当作指数读入，需要退回
+	if strings.HasSuffix(numStr, "e") || strings.HasSuffix(numStr, "E") {
+		numStr = numStr[:len(numStr)-1]
+		p.index--
+	}
+	unitStart := p.index
+	for {
+		char, ok := p.getChar(0)
+		if !ok || !(unicode.IsLetter(char) || char == '%' || char == '°' || char == '/' ||
+			(p.index > unitStart && unicode.IsDigit(char))) {
+			break
+		}
+		p.index++
+	}
+	// 单位之后必须是值的边界，否则不是单位而是其他内容
+	next, ok := p.getChar(0)
+	if p.index == unitStart || (ok && !unicode.IsSpace(next) && !strings.ContainsRune(",;}]", next)) {
+		p.index = unitStart
+		return nil, false
+	}
+
+	unit := string(p.jsonStr[unitStart:p.index])
+	p.addDiagnostic(KindUnitSuffix, SeverityInfo, start, p.index-start,
+		"number has unit suffix "+strconv.Quote(unit), "write the unit in a separate field")
+	if ctx, _ := p.context.current(); p.units == UnitsSplit && ctx == inObjectValue {
+		p.pendingUnit = unit
+		return p.numberValue(start, numStr), true
+	}
+	return string(p.jsonStr[start:p.index]), true
+}
+
+// addUnitMember 将 key 的单位写入同级成员 <key>_unit，该键已被占用时与 sanitizeObjectKeys 一样追加 _2、_3 等后缀，返回实际使用的键
+func (p *Parser) addUnitMember(obj map[string]interface{}, key, unit string) string {
+	name := key + unitSuffix
+	for i := 2; ; i++ {
+		if _, exists := obj[name]; !exists {
+			break
+		}
+		name = key + unitSuffix + "_" + strconv.Itoa(i)
+	}
+	if name != key+unitSuffix {
+		p.addDiagnostic(KindUnitMemberRenamed, SeverityWarning, p.index, 0,
+			fmt.Sprintf("member %q already exists, the unit of %q was written to %q", key+unitSuffix, key, name),
+			"rename the existing member or write the unit in a separate field")
+	}
+	obj[name] = unit
+	return name
+}
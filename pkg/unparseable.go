@@ -0,0 +1,61 @@
+package pkg
+
+import (
+	"errors"
+	"fmt"
+)
+
+// UnparseablePolicy 决定对象成员缺少值或值无法解析时如何处理
+type UnparseablePolicy struct {
+	mode     unparseableMode
+	sentinel interface{}
+}
+
+type unparseableMode int
+
+const (
+	unparseableEmpty unparseableMode = iota
+	unparseableNull
+	unparseableSentinel
+	unparseableDrop
+	unparseableError
+)
+
+var (
+	// UnparseableEmpty 存为空字符串（默认）
+	UnparseableEmpty = UnparseablePolicy{}
+	// UnparseableNull 存为 null
+	UnparseableNull = UnparseablePolicy{mode: unparseableNull}
+	// UnparseableDrop 丢弃该成员
+	UnparseableDrop = UnparseablePolicy{mode: unparseableDrop}
+	// UnparseableError 返回 ErrUnparseableValue
+	UnparseableError = UnparseablePolicy{mode: unparseableError}
+)
+
+// Sentinel 存为调用方指定的值 v，便于在下游与真实的空字符串区分
+func Sentinel(v interface{}) UnparseablePolicy {
+	return UnparseablePolicy{mode: unparseableSentinel, sentinel: v}
+}
+
+// ErrUnparseableValue 表示对象成员缺少值或值无法解析
+var ErrUnparseableValue = errors.New("object member has no parseable value")
+
+// unparseableValue 按 WithUnparseableValue 返回成员 key 的替代值，keep 为 false 时丢弃该成员
+func (p *Parser) unparseableValue(key string, offset int) (value interface{}, keep bool) {
+	p.addDiagnostic(KindUnparseableValue, SeverityWarning, offset, 0,
+		fmt.Sprintf("value of key %q is missing or could not be parsed", key), "provide a value for the key")
+	switch p.unparseable.mode {
+	case unparseableNull:
+		return nil, true
+	case unparseableSentinel:
+		return p.unparseable.sentinel, true
+	case unparseableDrop:
+		return nil, false
+	case unparseableError:
+		if p.valueErr == nil {
+			line, column := p.position(offset)
+			p.valueErr = fmt.Errorf("%w: key %q at line %d, column %d", ErrUnparseableValue, key, line, column)
+		}
+	}
+	return "", true
+}
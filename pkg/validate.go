@@ -0,0 +1,36 @@
+package pkg
+
+import "strings"
+
+// RepairAndValidate 修复JSON并检查必填字段，返回解析结果以及缺失（或为 null）的字段列表
+// 字段支持使用 "." 分隔的嵌套路径，例如 "user.name"
+func RepairAndValidate(jsonStr string, required []string) (interface{}, []string, error) {
+	value, err := Loads(jsonStr)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	missing := make([]string, 0)
+	for _, field := range required {
+		if v, ok := lookupField(value, field); !ok || v == nil {
+			missing = append(missing, field)
+		}
+	}
+	return value, missing, nil
+}
+
+// lookupField 按 "." 分隔的路径在对象中查找字段
+func lookupField(value interface{}, path string) (interface{}, bool) {
+	current := value
+	for _, key := range strings.Split(path, ".") {
+		obj, ok := current.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		current, ok = obj[key]
+		if !ok {
+			return nil, false
+		}
+	}
+	return current, true
+}
@@ -0,0 +1,47 @@
+package pkg
+
+import "errors"
+
+// SkipChildren 可由 Walk 的回调返回，表示不再遍历当前对象或数组的子值
+var SkipChildren = errors.New("skip children")
+
+// Walk 以深度优先的顺序遍历 Loads 返回的值，对每个值（包括根值）调用 fn
+// path 的格式与诊断信息一致，例如 $.items[0].name；对象的键按字典序遍历，
+// 因为解析结果使用 map 保存对象，原始键顺序不会保留
+// fn 返回 SkipChildren 时跳过该值的子值，返回其他错误时停止遍历并返回该错误
+func Walk(v interface{}, fn func(path string, value interface{}) error) error {
+	err := walk("$", v, fn)
+	if errors.Is(err, SkipChildren) {
+		return nil
+	}
+	return err
+}
+
+func walk(path string, value interface{}, fn func(path string, value interface{}) error) error {
+	if err := fn(path, value); err != nil {
+		return err
+	}
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for _, key := range sortedKeys(v) {
+			if err := walkChild(childPath(path, key), v[key], fn); err != nil {
+				return err
+			}
+		}
+	case []interface{}:
+		for i, child := range v {
+			if err := walkChild(indexPath(path, i), child, fn); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// walkChild 遍历子值，子值返回的 SkipChildren 只影响其自身
+func walkChild(path string, value interface{}, fn func(path string, value interface{}) error) error {
+	if err := walk(path, value, fn); err != nil && !errors.Is(err, SkipChildren) {
+		return err
+	}
+	return nil
+}